@@ -29,10 +29,14 @@ func (p *Program) String() string {
 }
 
 // --------------------LET
+// LetStatement binds Value to Name, or destructures it into Pattern when the
+// target is an array or hash pattern; exactly one of Name and Pattern is set.
+// A const statement is a LetStatement whose Token is CONST.
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Pattern Pattern
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -41,7 +45,11 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -53,6 +61,20 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// IsConst reports whether the bindings are constants that cannot be
+// redeclared in the same scope.
+func (ls *LetStatement) IsConst() bool {
+	return ls.Token.Type == token.CONST
+}
+
+// Names lists every identifier the statement binds.
+func (ls *LetStatement) Names() []*Identifier {
+	if ls.Pattern != nil {
+		return PatternNames(ls.Pattern)
+	}
+	return []*Identifier{ls.Name}
+}
+
 // --------------------RETURN
 type ReturnStatement struct {
 	Token       token.Token
@@ -82,6 +104,48 @@ func (rs *ReturnStatement) String() string {
 	return builder.String()
 }
 
+// --------------------IMPORT
+// ImportStatement binds a module either as a whole under Alias
+// (import "lib" as lib) or export by export (import { a, b } from "lib").
+type ImportStatement struct {
+	Token token.Token
+	Path  *StringLiteral
+	Alias *Identifier
+	Names []*Identifier
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	if is.Alias != nil {
+		out.WriteString(`"` + is.Path.Value + `" as ` + is.Alias.String())
+	} else {
+		names := []string{}
+		for _, name := range is.Names {
+			names = append(names, name.String())
+		}
+		out.WriteString("{ " + strings.Join(names, ", ") + ` } from "` + is.Path.Value + `"`)
+	}
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// --------------------EXPORT
+type ExportStatement struct {
+	Token     token.Token
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
 // --------------------RETURN
 type ExpressionStatement struct {
 	Token      token.Token
@@ -124,6 +188,116 @@ type Identifier struct {
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 func (i *Identifier) String() string       { return i.Value }
+func (i *Identifier) patternNode()         {}
+
+// ArrayPattern is [a, [b, c], ...rest]. Rest, when set, takes the elements
+// left over after Elements.
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) expressionNode()      {}
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// HashPattern is {name, age: years, "id": id, ...rest}. An identifier key
+// stands for the string of its name; Rest takes the remaining pairs.
+type HashPattern struct {
+	Token token.Token
+	Pairs []*HashPatternPair
+	Rest  *Identifier
+}
+
+type HashPatternPair struct {
+	Key   Expression
+	Value Pattern
+}
+
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		if ident, ok := pair.Value.(*Identifier); ok && ident == pair.Key {
+			pairs = append(pairs, pair.Key.String())
+		} else {
+			pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+		}
+	}
+	if hp.Rest != nil {
+		pairs = append(pairs, "..."+hp.Rest.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// LiteralPattern matches values equal to a number, string or boolean literal.
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) expressionNode()      {}
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// PatternNames lists the identifiers a pattern binds, in source order.
+func PatternNames(pattern Pattern) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		if pattern.Value == "_" {
+			return nil
+		}
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		names := []*Identifier{}
+		for _, el := range pattern.Elements {
+			names = append(names, PatternNames(el)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, pattern.Rest)
+		}
+		return names
+	case *HashPattern:
+		names := []*Identifier{}
+		for _, pair := range pattern.Pairs {
+			names = append(names, PatternNames(pair.Value)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, pattern.Rest)
+		}
+		return names
+	default:
+		return nil
+	}
+}
 
 type Boolean struct {
 	Token token.Token
@@ -161,6 +335,33 @@ func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+// TemplateLiteral is a backtick string. Strings holds the text around the
+// ${...} Expressions, so it always has one more element than Expressions.
+type TemplateLiteral struct {
+	Token       token.Token
+	Strings     []string
+	Expressions []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode()      {}
+func (tl *TemplateLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("`")
+	for i, str := range tl.Strings {
+		out.WriteString(str)
+		if i < len(tl.Expressions) {
+			out.WriteString("${")
+			out.WriteString(tl.Expressions[i].String())
+			out.WriteString("}")
+		}
+	}
+	out.WriteString("`")
+
+	return out.String()
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -263,11 +464,101 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// MemberExpression is obj.name, sugar for obj["name"] on hashes and the way
+// to reach a module's exports.
+type MemberExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// PipeExpression is x |> f(a), which calls f(x, a). A right-hand side that is
+// not a call is called with x alone: x |> f is f(x).
+type PipeExpression struct {
+	Token token.Token
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PipeExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Left.String())
+	out.WriteString(" |> ")
+	out.WriteString(pe.Right.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// MatchExpression tries each arm in order against Subject and evaluates the
+// body of the first whose pattern matches and whose guard, if any, holds.
+type MatchExpression struct {
+	Token   token.Token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+// MatchArm is pattern if guard => body. Body is an Expression or a
+// *BlockStatment.
+type MatchArm struct {
+	Pattern Pattern
+	Guard   Expression
+	Body    Node
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		s := arm.Pattern.String()
+		if arm.Guard != nil {
+			s += " if " + arm.Guard.String()
+		}
+		arms = append(arms, s+" => "+arm.Body.String())
+	}
+
+	out.WriteString(me.TokenLiteral())
+	out.WriteString(" (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// IFExpression is an if with any number of else if arms: Arms holds the if
+// arm first, and Alternative, if set, is the final else block.
 type IFExpression struct {
 	Token       token.Token
+	Arms        []*IfArm
+	Alternative *BlockStatment
+}
+
+type IfArm struct {
 	Condition   Expression
 	Consequence *BlockStatment
-	Alternative *BlockStatment
 }
 
 func (ie *IFExpression) expressionNode()      {}
@@ -275,14 +566,19 @@ func (ie *IFExpression) TokenLiteral() string { return ie.Token.Literal }
 func (ie *IFExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ie.TokenLiteral())
-	out.WriteString(ie.Condition.String())
-	out.WriteString(" ")
+	for idx, arm := range ie.Arms {
+		if idx > 0 {
+			out.WriteString(" else ")
+		}
+		out.WriteString(ie.TokenLiteral() + " ")
+		out.WriteString(arm.Condition.String())
+		out.WriteString(" ")
+		out.WriteString(arm.Consequence.String())
+	}
 
 	if ie.Alternative != nil {
-		out.WriteString("else ")
+		out.WriteString(" else ")
 		out.WriteString(ie.Alternative.String())
-
 	}
 
 	return out.String()
@@ -290,7 +586,7 @@ func (ie *IFExpression) String() string {
 
 type FunctionLiteral struct {
 	Token      token.Token
-	Parameters []*Identifier
+	Parameters []Pattern
 	Body       *BlockStatment
 }
 
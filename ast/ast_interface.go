@@ -14,3 +14,11 @@ type Expression interface {
 	Node
 	expressionNode()
 }
+
+// Pattern is the target of a binding: an *Identifier, an *ArrayPattern or a
+// *HashPattern, or in a match arm also a *LiteralPattern. The identifier _
+// matches anything without binding it.
+type Pattern interface {
+	Expression
+	patternNode()
+}
@@ -2,113 +2,336 @@ package evaluator
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/arthurlee945/monkey.on/object"
 )
 
-var builtins = map[string]*object.Builtin{
-	"len": {
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, expected=1", len(args))
-			}
+// Capability names a group of builtins that can be granted to an Evaluator.
+type Capability string
+
+const (
+	CORE_CAP   = "CORE"   // pure functions over values, no side effects
+	STDIO_CAP  = "STDIO"  // reads and writes the evaluator's standard streams
+	RANDOM_CAP = "RANDOM" // draws from the evaluator's random source
+	FS_CAP     = "FS"     // reads and writes the evaluator's file system
+	CLOCK_CAP  = "CLOCK"  // reads the evaluator's clock and sleeps on it
+)
+
+// Profile is a preset list of capabilities.
+type Profile []Capability
+
+var (
+	PURE_PROFILE  = Profile{CORE_CAP}
+	STDIO_PROFILE = Profile{CORE_CAP, STDIO_CAP}
+	FULL_PROFILE  = Profile{CORE_CAP, STDIO_CAP, RANDOM_CAP, FS_CAP, CLOCK_CAP}
+)
+
+var capabilities = map[Capability][]func(e *Evaluator) map[string]*object.Builtin{
+	CORE_CAP:   {coreBuiltins, typeBuiltins, arrayBuiltins, hashBuiltins, stringBuiltins, mathBuiltins, jsonBuiltins, regexBuiltins},
+	STDIO_CAP:  {stdioBuiltins},
+	RANDOM_CAP: {randomBuiltins},
+	FS_CAP:     {fsBuiltins},
+	CLOCK_CAP:  {timeBuiltins},
+}
 
-			switch arg := args[0].(type) {
-			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
-			case *object.Array:
-				return &object.Integer{Value: int64(len(arg.Elements))}
-			default:
-				return newError("argument to 'len' not supported, got %s", arg.Type())
+// capabilityConstants are the named values, rather than functions, that a
+// capability brings along.
+var capabilityConstants = map[Capability]map[string]object.Object{
+	CORE_CAP: mathConstants,
+}
+
+// Use grants every builtin belonging to the given capabilities.
+func (e *Evaluator) Use(caps ...Capability) {
+	for _, c := range caps {
+		for _, builtins := range capabilities[c] {
+			for name, builtin := range builtins(e) {
+				e.builtins[name] = builtin
 			}
+		}
+		for name, constant := range capabilityConstants[c] {
+			e.constants[name] = constant
+		}
+	}
+}
+
+// Register adds or replaces a single builtin.
+func (e *Evaluator) Register(name string, fn object.BuiltinFunction) {
+	e.builtins[name] = &object.Builtin{Fn: fn}
+}
+
+// Unregister removes a builtin or constant so scripts can no longer reach it.
+func (e *Evaluator) Unregister(name string) {
+	delete(e.builtins, name)
+	delete(e.constants, name)
+}
+
+// Builtin looks up a registered builtin by name.
+func (e *Evaluator) Builtin(name string) (*object.Builtin, bool) {
+	builtin, ok := e.builtins[name]
+	return builtin, ok
+}
+
+// Constant looks up a constant such as PI granted by a capability.
+func (e *Evaluator) Constant(name string) (object.Object, bool) {
+	constant, ok := e.constants[name]
+	return constant, ok
+}
+
+// Builtins lists the names of every registered builtin in sorted order.
+func (e *Evaluator) Builtins() []string {
+	names := make([]string, 0, len(e.builtins))
+	for name := range e.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// maxLength bounds the bytes of a string and the elements of an array that a
+// builtin builds from a count, so a huge count is an error rather than a
+// crash or an exhausted heap.
+const maxLength = 1 << 26
+
+func wrongArgumentCount(got, expected int) *object.Error {
+	return newError("wrong number of arguments. got=%d, expected=%d", got, expected)
+}
+
+// argumentError reports an argument of the wrong type. pos counts from 1.
+func argumentError(name string, pos int, expected string, got object.Object) *object.Error {
+	return newError("argument %d to `%s` must be %s, got %s", pos, name, expected, got.Type())
+}
+
+func isCallable(obj object.Object) bool {
+	return obj.Type() == object.FUNCTION_OBJ || obj.Type() == object.BUILTIN_OBJ
+}
+
+func coreBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"len": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, expected=1", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *object.String:
+					return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+				case *object.Array:
+					return &object.Integer{Value: int64(len(arg.Elements))}
+				case *object.Hash:
+					return &object.Integer{Value: int64(len(arg.Pairs))}
+				default:
+					return newError("argument to 'len' not supported, got %s", arg.Type())
+				}
+			},
 		},
-	},
-	"first": {
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, expected=1", len(args))
-			}
+		"first": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, expected=1", len(args))
+				}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `first` must be ARRAY, got %s", args[0].Type())
-			}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return newError("argument to `first` must be ARRAY, got %s", args[0].Type())
+				}
 
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[0]
-			}
+				arr := args[0].(*object.Array)
+				if len(arr.Elements) > 0 {
+					return arr.Elements[0]
+				}
 
-			return NULL
+				return NULL
+			},
 		},
-	},
-	"last": {
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, expected=1", len(args))
-			}
+		"last": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, expected=1", len(args))
+				}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
-			}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
+				}
 
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[len(arr.Elements)-1]
-			}
+				arr := args[0].(*object.Array)
+				if len(arr.Elements) > 0 {
+					return arr.Elements[len(arr.Elements)-1]
+				}
 
-			return NULL
+				return NULL
+			},
 		},
-	},
-	"rest": {
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, expected=1", len(args))
-			}
+		"rest": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, expected=1", len(args))
+				}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `rest` must be ARRAY, got %s", args[0].Type())
-			}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return newError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+				}
+
+				arr := args[0].(*object.Array)
+				length := len(arr.Elements)
+				if length > 0 {
+					newElements := make([]object.Object, length-1)
+					copy(newElements, arr.Elements[1:length])
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length > 0 {
-				newElements := make([]object.Object, length-1)
-				copy(newElements, arr.Elements[1:length])
+					return &object.Array{Elements: newElements}
+
+				}
+
+				return NULL
+			},
+		},
+		"push": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=2", len(args))
+				}
+
+				if args[0].Type() != object.ARRAY_OBJ {
+					return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+				}
+
+				arr := args[0].(*object.Array)
+				length := len(arr.Elements)
+
+				newElements := make([]object.Object, length+1)
+				copy(newElements, arr.Elements)
+				newElements[length] = args[1]
 
 				return &object.Array{Elements: newElements}
+			},
+		},
+		"freeze": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
 
-			}
+				freeze(args[0])
+				return args[0]
+			},
+		},
+		"is_frozen": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
 
-			return NULL
+				switch arg := args[0].(type) {
+				case *object.Array:
+					return nativeBoolToBooleanObject(arg.Frozen)
+				case *object.Hash:
+					return nativeBoolToBooleanObject(arg.Frozen)
+				default:
+					// every other value is immutable already
+					return TRUE
+				}
+			},
 		},
-	},
-	"push": {
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, expected=2", len(args))
-			}
+	}
+}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
-			}
+// freeze marks obj and every array or hash inside it as frozen, in place.
+// Frozen values still work with builtins such as push and merge, which return
+// an unfrozen copy; only delete, the one builtin that edits its argument,
+// refuses them.
+func freeze(obj object.Object) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, el := range obj.Elements {
+			freeze(el)
+		}
+	case *object.Hash:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, pair := range obj.Pairs {
+			freeze(pair.Value)
+		}
+	}
+}
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
+func stdioBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"puts": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				for _, arg := range args {
+					fmt.Fprintln(e.out, arg.Inspect())
+				}
 
-			newElements := make([]object.Object, length+1)
-			copy(newElements, arr.Elements)
-			newElements[length] = args[1]
+				return NULL
+			},
+		},
+		"print": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				for _, arg := range args {
+					fmt.Fprint(e.out, arg.Inspect())
+				}
 
-			return &object.Array{Elements: newElements}
+				return NULL
+			},
 		},
-	},
-	"puts": {
-		Fn: func(args ...object.Object) object.Object {
-			for _, arg := range args {
-				fmt.Println(arg.Inspect())
-			}
+		"eprint": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				for _, arg := range args {
+					fmt.Fprint(e.errOut, arg.Inspect())
+				}
 
-			return NULL
+				return NULL
+			},
 		},
-	},
+		"input": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) > 1 {
+					return newError("wrong number of arguments. got=%d, expected=0 or 1", len(args))
+				}
+				if len(args) == 1 {
+					prompt, ok := args[0].(*object.String)
+					if !ok {
+						return argumentError("input", 1, object.STRING_OBJ, args[0])
+					}
+					fmt.Fprint(e.out, prompt.Value)
+				}
+
+				return e.readLine()
+			},
+		},
+		"read_line": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 0 {
+					return wrongArgumentCount(len(args), 0)
+				}
+
+				return e.readLine()
+			},
+		},
+	}
+}
+
+// readLine reads the next line of input without its line ending, or NULL once
+// the input is exhausted.
+func (e *Evaluator) readLine() object.Object {
+	line, err := e.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return newError("cannot read input: %s", err)
+	}
+	if err == io.EOF && line == "" {
+		return NULL
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return &object.String{Value: line}
 }
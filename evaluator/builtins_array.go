@@ -0,0 +1,759 @@
+package evaluator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+func arrayBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"map": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				collection, fn, err := collectionAndFunction("map", args)
+				if err != nil {
+					return err
+				}
+
+				mapped := []object.Object{}
+				err = forEach(ctx, collection, fn, func(result object.Object, _ ...object.Object) {
+					mapped = append(mapped, result)
+				})
+				if err != nil {
+					return err
+				}
+
+				return &object.Array{Elements: mapped}
+			},
+		},
+		"filter": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				collection, fn, err := collectionAndFunction("filter", args)
+				if err != nil {
+					return err
+				}
+
+				if _, ok := collection.(*object.Hash); ok {
+					pairs := make(map[object.HashKey]object.HashPair)
+					err = forEach(ctx, collection, fn, func(result object.Object, entry ...object.Object) {
+						if isTruthy(result) {
+							pairs[entry[0].(object.Hashable).HashKey()] = object.HashPair{Key: entry[0], Value: entry[1]}
+						}
+					})
+					if err != nil {
+						return err
+					}
+					return &object.Hash{Pairs: pairs}
+				}
+
+				filtered := []object.Object{}
+				err = forEach(ctx, collection, fn, func(result object.Object, el ...object.Object) {
+					if isTruthy(result) {
+						filtered = append(filtered, el[0])
+					}
+				})
+				if err != nil {
+					return err
+				}
+
+				return &object.Array{Elements: filtered}
+			},
+		},
+		"each": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				collection, fn, err := collectionAndFunction("each", args)
+				if err != nil {
+					return err
+				}
+
+				err = forEach(ctx, collection, fn, func(object.Object, ...object.Object) {})
+				if err != nil {
+					return err
+				}
+
+				return NULL
+			},
+		},
+		"reduce": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 && len(args) != 3 {
+					return newError("wrong number of arguments. got=%d, expected=2 or 3", len(args))
+				}
+				arr, fn, err := arrayAndFunction("reduce", args[:2])
+				if err != nil {
+					return err
+				}
+
+				elements := arr.Elements
+				var acc object.Object
+				if len(args) == 3 {
+					acc = args[2]
+				} else if len(elements) > 0 {
+					acc, elements = elements[0], elements[1:]
+				} else {
+					return newError("reduce of empty array with no initial value")
+				}
+
+				for _, el := range elements {
+					acc = ctx.Apply(fn, acc, el)
+					if isError(acc) {
+						return acc
+					}
+				}
+
+				return acc
+			},
+		},
+		"sort": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("sort", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				compare := compareObjects
+				if len(args) == 2 {
+					if !isCallable(args[1]) {
+						return argumentError("sort", 2, object.FUNCTION_OBJ, args[1])
+					}
+					compare = comparator(ctx, args[1])
+				}
+
+				return sortElements(args[0].(*object.Array).Elements, compare)
+			},
+		},
+		"sort_by": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				arr, fn, err := arrayAndFunction("sort_by", args)
+				if err != nil {
+					return err
+				}
+
+				keys := make(map[object.Object]object.Object, len(arr.Elements))
+				for _, el := range arr.Elements {
+					if _, ok := keys[el]; ok {
+						continue
+					}
+					key := ctx.Apply(fn, el)
+					if isError(key) {
+						return key
+					}
+					keys[el] = key
+				}
+
+				return sortElements(arr.Elements, func(a, b object.Object) (int, object.Object) {
+					return compareObjects(keys[a], keys[b])
+				})
+			},
+		},
+		"find": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				arr, fn, err := arrayAndFunction("find", args)
+				if err != nil {
+					return err
+				}
+
+				for _, el := range arr.Elements {
+					result := ctx.Apply(fn, el)
+					if isError(result) {
+						return result
+					}
+					if isTruthy(result) {
+						return el
+					}
+				}
+
+				return NULL
+			},
+		},
+		"any": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				arr, fn, err := arrayAndFunction("any", args)
+				if err != nil {
+					return err
+				}
+
+				for _, el := range arr.Elements {
+					result := ctx.Apply(fn, el)
+					if isError(result) {
+						return result
+					}
+					if isTruthy(result) {
+						return TRUE
+					}
+				}
+
+				return FALSE
+			},
+		},
+		"all": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				arr, fn, err := arrayAndFunction("all", args)
+				if err != nil {
+					return err
+				}
+
+				for _, el := range arr.Elements {
+					result := ctx.Apply(fn, el)
+					if isError(result) {
+						return result
+					}
+					if !isTruthy(result) {
+						return FALSE
+					}
+				}
+
+				return TRUE
+			},
+		},
+		"reverse": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("reverse", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				elements := args[0].(*object.Array).Elements
+				reversed := make([]object.Object, len(elements))
+				for idx, el := range elements {
+					reversed[len(elements)-1-idx] = el
+				}
+
+				return &object.Array{Elements: reversed}
+			},
+		},
+		"concat": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				concatenated := []object.Object{}
+				for idx, arg := range args {
+					arr, ok := arg.(*object.Array)
+					if !ok {
+						return argumentError("concat", idx+1, object.ARRAY_OBJ, arg)
+					}
+					concatenated = append(concatenated, arr.Elements...)
+				}
+
+				return &object.Array{Elements: concatenated}
+			},
+		},
+		"slice": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 && len(args) != 3 {
+					return newError("wrong number of arguments. got=%d, expected=2 or 3", len(args))
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("slice", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				elements := args[0].(*object.Array).Elements
+				start, end, err := sliceBounds("slice", args[1:], len(elements))
+				if err != nil {
+					return err
+				}
+
+				sliced := make([]object.Object, end-start)
+				copy(sliced, elements[start:end])
+
+				return &object.Array{Elements: sliced}
+			},
+		},
+		"index_of": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return wrongArgumentCount(len(args), 2)
+				}
+
+				switch haystack := args[0].(type) {
+				case *object.Array:
+					for idx, el := range haystack.Elements {
+						if objectsEqual(el, args[1]) {
+							return &object.Integer{Value: int64(idx)}
+						}
+					}
+					return &object.Integer{Value: -1}
+				case *object.String:
+					needle, ok := args[1].(*object.String)
+					if !ok {
+						return argumentError("index_of", 2, object.STRING_OBJ, args[1])
+					}
+					return &object.Integer{Value: int64(runeIndex(haystack.Value, needle.Value))}
+				default:
+					return argumentError("index_of", 1, "ARRAY or STRING", args[0])
+				}
+			},
+		},
+		"contains": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return wrongArgumentCount(len(args), 2)
+				}
+
+				switch haystack := args[0].(type) {
+				case *object.Array:
+					for _, el := range haystack.Elements {
+						if objectsEqual(el, args[1]) {
+							return TRUE
+						}
+					}
+					return FALSE
+				case *object.String:
+					needle, ok := args[1].(*object.String)
+					if !ok {
+						return argumentError("contains", 2, object.STRING_OBJ, args[1])
+					}
+					return nativeBoolToBooleanObject(strings.Contains(haystack.Value, needle.Value))
+				default:
+					return argumentError("contains", 1, "ARRAY or STRING", args[0])
+				}
+			},
+		},
+		"unique": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("unique", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				unique := []object.Object{}
+			elements:
+				for _, el := range args[0].(*object.Array).Elements {
+					for _, seen := range unique {
+						if objectsEqual(el, seen) {
+							continue elements
+						}
+					}
+					unique = append(unique, el)
+				}
+
+				return &object.Array{Elements: unique}
+			},
+		},
+		"flatten": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("flatten", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				depth := int64(1)
+				if len(args) == 2 {
+					d, ok := args[1].(*object.Integer)
+					if !ok {
+						return argumentError("flatten", 2, object.INTEGER_OBJ, args[1])
+					}
+					depth = d.Value
+				}
+
+				return &object.Array{Elements: flattenElements(args[0].(*object.Array).Elements, depth)}
+			},
+		},
+		"zip": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) == 0 {
+					return newError("wrong number of arguments. got=0, expected at least 1")
+				}
+
+				length := -1
+				for idx, arg := range args {
+					arr, ok := arg.(*object.Array)
+					if !ok {
+						return argumentError("zip", idx+1, object.ARRAY_OBJ, arg)
+					}
+					if length == -1 || len(arr.Elements) < length {
+						length = len(arr.Elements)
+					}
+				}
+
+				zipped := make([]object.Object, length)
+				for i := range zipped {
+					tuple := make([]object.Object, len(args))
+					for j, arg := range args {
+						tuple[j] = arg.(*object.Array).Elements[i]
+					}
+					zipped[i] = &object.Array{Elements: tuple}
+				}
+
+				return &object.Array{Elements: zipped}
+			},
+		},
+		"range": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) < 1 || len(args) > 3 {
+					return newError("wrong number of arguments. got=%d, expected=1, 2 or 3", len(args))
+				}
+
+				bounds := []int64{0, 0, 1}
+				for idx, arg := range args {
+					n, ok := arg.(*object.Integer)
+					if !ok {
+						return argumentError("range", idx+1, object.INTEGER_OBJ, arg)
+					}
+					bounds[idx] = n.Value
+				}
+				if len(args) == 1 {
+					bounds[0], bounds[1] = 0, bounds[0]
+				}
+
+				start, end, step := bounds[0], bounds[1], bounds[2]
+				if step == 0 {
+					return newError("range step must not be zero")
+				}
+
+				// Count the elements in uint64 so that neither the span nor the
+				// step past the last element can overflow.
+				var count uint64
+				if step > 0 && start < end {
+					count = (uint64(end-start)-1)/uint64(step) + 1
+				} else if step < 0 && start > end {
+					count = (uint64(start-end)-1)/uint64(-step) + 1
+				}
+				if count > maxLength {
+					return newError("range has too many elements. limit=%d", maxLength)
+				}
+
+				elements := make([]object.Object, count)
+				n := start
+				for idx := range elements {
+					elements[idx] = &object.Integer{Value: n}
+					n += step
+				}
+
+				return &object.Array{Elements: elements}
+			},
+		},
+		"join": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("join", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				separator := ""
+				if len(args) == 2 {
+					sep, ok := args[1].(*object.String)
+					if !ok {
+						return argumentError("join", 2, object.STRING_OBJ, args[1])
+					}
+					separator = sep.Value
+				}
+
+				parts := []string{}
+				for _, el := range args[0].(*object.Array).Elements {
+					parts = append(parts, stringify(el))
+				}
+
+				return &object.String{Value: strings.Join(parts, separator)}
+			},
+		},
+		"sum": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				if args[0].Type() != object.ARRAY_OBJ {
+					return argumentError("sum", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				var total object.Object = &object.Integer{Value: 0}
+				for _, el := range args[0].(*object.Array).Elements {
+					if !isNumber(el) {
+						return newError("cannot sum %s", el.Type())
+					}
+					total = evalInfixExpression("+", total, el)
+				}
+
+				return total
+			},
+		},
+		"min": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return extremum("min", args, -1)
+			},
+		},
+		"max": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return extremum("max", args, 1)
+			},
+		},
+	}
+}
+
+// arrayAndFunction validates the (array, function) argument pair shared by
+// the higher-order array builtins.
+func arrayAndFunction(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, wrongArgumentCount(len(args), 2)
+	}
+	if args[0].Type() != object.ARRAY_OBJ {
+		return nil, nil, argumentError(name, 1, object.ARRAY_OBJ, args[0])
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, argumentError(name, 2, object.FUNCTION_OBJ, args[1])
+	}
+	return args[0].(*object.Array), args[1], nil
+}
+
+// collectionAndFunction is arrayAndFunction for builtins that also iterate
+// over hashes.
+func collectionAndFunction(name string, args []object.Object) (object.Object, object.Object, object.Object) {
+	if len(args) != 2 {
+		return nil, nil, wrongArgumentCount(len(args), 2)
+	}
+	if args[0].Type() != object.ARRAY_OBJ && args[0].Type() != object.HASH_OBJ {
+		return nil, nil, argumentError(name, 1, "ARRAY or HASH", args[0])
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, argumentError(name, 2, object.FUNCTION_OBJ, args[1])
+	}
+	return args[0], args[1], nil
+}
+
+// forEach applies fn to every element of an array, or to every key and value
+// of a hash in key order, handing each result and the arguments that produced
+// it to visit. It stops at the first error.
+func forEach(ctx object.Context, collection, fn object.Object, visit func(result object.Object, args ...object.Object)) object.Object {
+	var calls [][]object.Object
+	switch collection := collection.(type) {
+	case *object.Array:
+		for _, el := range collection.Elements {
+			calls = append(calls, []object.Object{el})
+		}
+	case *object.Hash:
+		for _, pair := range collection.SortedPairs() {
+			calls = append(calls, []object.Object{pair.Key, pair.Value})
+		}
+	}
+
+	for _, args := range calls {
+		result := ctx.Apply(fn, args...)
+		if isError(result) {
+			return result
+		}
+		visit(result, args...)
+	}
+	return nil
+}
+
+// compareObjects orders numbers numerically and strings lexically; any other
+// combination cannot be compared.
+func compareObjects(a, b object.Object) (int, object.Object) {
+	switch {
+	case a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ:
+		left, right := a.(*object.Integer).Value, b.(*object.Integer).Value
+		switch {
+		case left < right:
+			return -1, nil
+		case left > right:
+			return 1, nil
+		}
+		return 0, nil
+	case isNumber(a) && isNumber(b):
+		left, right := toFloat(a), toFloat(b)
+		switch {
+		case left < right:
+			return -1, nil
+		case left > right:
+			return 1, nil
+		}
+		return 0, nil
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return strings.Compare(a.(*object.String).Value, b.(*object.String).Value), nil
+	case a.Type() == object.TIME_OBJ && b.Type() == object.TIME_OBJ:
+		return a.(*object.Time).Value.Compare(b.(*object.Time).Value), nil
+	case a.Type() == object.DURATION_OBJ && b.Type() == object.DURATION_OBJ:
+		left, right := a.(*object.Duration).Value, b.(*object.Duration).Value
+		switch {
+		case left < right:
+			return -1, nil
+		case left > right:
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, newError("cannot compare %s with %s", a.Type(), b.Type())
+	}
+}
+
+// comparator adapts a Monkey function into a compare func. The function may
+// return an INTEGER (negative, zero, positive) or a BOOLEAN meaning "a < b".
+func comparator(ctx object.Context, fn object.Object) func(a, b object.Object) (int, object.Object) {
+	return func(a, b object.Object) (int, object.Object) {
+		switch result := ctx.Apply(fn, a, b).(type) {
+		case *object.Error:
+			return 0, result
+		case *object.Integer:
+			return int(result.Value), nil
+		case *object.Boolean:
+			if result.Value {
+				return -1, nil
+			}
+			return 0, nil
+		case nil:
+			return 0, newError("comparator must return INTEGER or BOOLEAN, got nothing")
+		default:
+			return 0, newError("comparator must return INTEGER or BOOLEAN, got %s", result.Type())
+		}
+	}
+}
+
+// sortElements returns a sorted copy of elements, stopping at the first
+// error raised by compare.
+func sortElements(elements []object.Object, compare func(a, b object.Object) (int, object.Object)) object.Object {
+	sorted := make([]object.Object, len(elements))
+	copy(sorted, elements)
+
+	var err object.Object
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		cmp, cmpErr := compare(sorted[i], sorted[j])
+		if cmpErr != nil {
+			err = cmpErr
+			return false
+		}
+		return cmp < 0
+	})
+	if err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: sorted}
+}
+
+// sliceBounds resolves optional start and end arguments against length.
+// Negative indices count from the end and out of range values are clamped.
+func sliceBounds(name string, args []object.Object, length int) (int, int, object.Object) {
+	bounds := []int{0, length}
+	for idx, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return 0, 0, argumentError(name, idx+2, object.INTEGER_OBJ, arg)
+		}
+		bound := int(n.Value)
+		if bound < 0 {
+			bound += length
+		}
+		bounds[idx] = max(0, min(bound, length))
+	}
+
+	start, end := bounds[0], bounds[1]
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
+
+func flattenElements(elements []object.Object, depth int64) []object.Object {
+	flattened := []object.Object{}
+	for _, el := range elements {
+		if arr, ok := el.(*object.Array); ok && depth > 0 {
+			flattened = append(flattened, flattenElements(arr.Elements, depth-1)...)
+		} else {
+			flattened = append(flattened, el)
+		}
+	}
+	return flattened
+}
+
+// extremum returns the smallest (sign -1) or largest (sign 1) of either a
+// single array argument or the arguments themselves.
+func extremum(name string, args []object.Object, sign int) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, expected at least 1")
+	}
+	values := args
+	if len(args) == 1 {
+		arr, ok := args[0].(*object.Array)
+		if !ok {
+			return argumentError(name, 1, object.ARRAY_OBJ, args[0])
+		}
+		values = arr.Elements
+	}
+	if len(values) == 0 {
+		return NULL
+	}
+
+	best := values[0]
+	for _, value := range values[1:] {
+		cmp, err := compareObjects(value, best)
+		if err != nil {
+			return err
+		}
+		if cmp*sign > 0 {
+			best = value
+		}
+	}
+	return best
+}
+
+// objectsEqual compares values structurally: numbers by value, arrays and
+// hashes element by element, everything else by identity.
+func objectsEqual(a, b object.Object) bool {
+	switch {
+	case isNumber(a) && isNumber(b):
+		cmp, _ := compareObjects(a, b)
+		return cmp == 0
+	case a.Type() != b.Type():
+		return false
+	}
+
+	switch a := a.(type) {
+	case *object.String:
+		return a.Value == b.(*object.String).Value
+	case *object.Boolean:
+		return a.Value == b.(*object.Boolean).Value
+	case *object.Array:
+		other := b.(*object.Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+		for idx, el := range a.Elements {
+			if !objectsEqual(el, other.Elements[idx]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		other := b.(*object.Hash)
+		if len(a.Pairs) != len(other.Pairs) {
+			return false
+		}
+		for key, pair := range a.Pairs {
+			otherPair, ok := other.Pairs[key]
+			if !ok || !objectsEqual(pair.Value, otherPair.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// stringify renders strings as their raw value and everything else through
+// Inspect, matching what `puts` prints.
+func stringify(obj object.Object) string {
+	if str, ok := obj.(*object.String); ok {
+		return str.Value
+	}
+	return obj.Inspect()
+}
+
+func toFloat(obj object.Object) float64 {
+	if f, ok := obj.(*object.Float); ok {
+		return f.Value
+	}
+	return float64(obj.(*object.Integer).Value)
+}
@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// FS builtins work on the evaluator's FileSystem (see SetFileSystem). Paths
+// are slash-separated and relative to its root.
+func fsBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"read_file": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				strs, err := e.fsArguments("read_file", args, 1)
+				if err != nil {
+					return err
+				}
+
+				data, readErr := fs.ReadFile(e.fs, strs[0])
+				if readErr != nil {
+					return fsError("read_file", strs[0], readErr)
+				}
+
+				return &object.String{Value: string(data)}
+			},
+		},
+		"write_file": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				strs, err := e.fsArguments("write_file", args, 2)
+				if err != nil {
+					return err
+				}
+
+				if writeErr := e.fs.WriteFile(strs[0], []byte(strs[1])); writeErr != nil {
+					return fsError("write_file", strs[0], writeErr)
+				}
+
+				return NULL
+			},
+		},
+		"append_file": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				strs, err := e.fsArguments("append_file", args, 2)
+				if err != nil {
+					return err
+				}
+
+				if appendErr := e.fs.AppendFile(strs[0], []byte(strs[1])); appendErr != nil {
+					return fsError("append_file", strs[0], appendErr)
+				}
+
+				return NULL
+			},
+		},
+		"list_dir": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) > 1 {
+					return newError("wrong number of arguments. got=%d, expected=0 or 1", len(args))
+				}
+				if len(args) == 0 {
+					args = []object.Object{&object.String{Value: "."}}
+				}
+				strs, err := e.fsArguments("list_dir", args, 1)
+				if err != nil {
+					return err
+				}
+
+				entries, readErr := fs.ReadDir(e.fs, strs[0])
+				if readErr != nil {
+					return fsError("list_dir", strs[0], readErr)
+				}
+
+				names := make([]string, len(entries))
+				for idx, entry := range entries {
+					names[idx] = entry.Name()
+				}
+
+				return stringArray(names)
+			},
+		},
+		"exists": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				strs, err := e.fsArguments("exists", args, 1)
+				if err != nil {
+					return err
+				}
+
+				_, statErr := fs.Stat(e.fs, strs[0])
+				if errors.Is(statErr, fs.ErrNotExist) {
+					return FALSE
+				}
+				if statErr != nil {
+					return fsError("exists", strs[0], statErr)
+				}
+
+				return TRUE
+			},
+		},
+		"remove": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				strs, err := e.fsArguments("remove", args, 1)
+				if err != nil {
+					return err
+				}
+
+				if removeErr := e.fs.Remove(strs[0]); removeErr != nil {
+					return fsError("remove", strs[0], removeErr)
+				}
+
+				return NULL
+			},
+		},
+	}
+}
+
+// fsArguments checks that a file system is configured and that args are count
+// strings.
+func (e *Evaluator) fsArguments(name string, args []object.Object, count int) ([]string, object.Object) {
+	if e.fs == nil {
+		return nil, newError("`%s` needs a file system, but none is configured", name)
+	}
+	if len(args) != count {
+		return nil, wrongArgumentCount(len(args), count)
+	}
+	return stringArguments(name, args...)
+}
+
+// fsError reports err against the path the script used, never the host path a
+// FileSystem may have resolved it to.
+func fsError(name, path string, err error) *object.Error {
+	for _, known := range []error{fs.ErrNotExist, fs.ErrExist, fs.ErrPermission, fs.ErrInvalid} {
+		if errors.Is(err, known) {
+			return newError("%s %q: %s", name, path, known)
+		}
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return newError("%s %q: %s", name, path, pathErr.Err)
+	}
+	return newError("%s %q: %s", name, path, err)
+}
@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// Hash builtins return keys, values and entries ordered by key (see
+// object.Hash.SortedPairs). `delete` is the only one that modifies the hash it
+// is given; `merge` always builds a new hash.
+func hashBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"keys": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				hash, err := hashArgument("keys", args, 1)
+				if err != nil {
+					return err
+				}
+
+				keys := []object.Object{}
+				for _, pair := range hash.SortedPairs() {
+					keys = append(keys, pair.Key)
+				}
+
+				return &object.Array{Elements: keys}
+			},
+		},
+		"values": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				hash, err := hashArgument("values", args, 1)
+				if err != nil {
+					return err
+				}
+
+				values := []object.Object{}
+				for _, pair := range hash.SortedPairs() {
+					values = append(values, pair.Value)
+				}
+
+				return &object.Array{Elements: values}
+			},
+		},
+		"entries": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				hash, err := hashArgument("entries", args, 1)
+				if err != nil {
+					return err
+				}
+
+				entries := []object.Object{}
+				for _, pair := range hash.SortedPairs() {
+					entries = append(entries, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+				}
+
+				return &object.Array{Elements: entries}
+			},
+		},
+		"has": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				hash, err := hashArgument("has", args, 2)
+				if err != nil {
+					return err
+				}
+
+				key, ok := args[1].(object.Hashable)
+				if !ok {
+					return newError("unusable as hash key: %s", args[1].Type())
+				}
+				_, found := hash.Pairs[key.HashKey()]
+
+				return nativeBoolToBooleanObject(found)
+			},
+		},
+		"get": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 && len(args) != 3 {
+					return newError("wrong number of arguments. got=%d, expected=2 or 3", len(args))
+				}
+				hash, err := hashArgument("get", args[:2], 2)
+				if err != nil {
+					return err
+				}
+
+				key, ok := args[1].(object.Hashable)
+				if !ok {
+					return newError("unusable as hash key: %s", args[1].Type())
+				}
+				if pair, ok := hash.Pairs[key.HashKey()]; ok {
+					return pair.Value
+				}
+				if len(args) == 3 {
+					return args[2]
+				}
+
+				return NULL
+			},
+		},
+		"delete": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				hash, err := hashArgument("delete", args, 2)
+				if err != nil {
+					return err
+				}
+				if hash.Frozen {
+					return newError("cannot delete from a frozen HASH")
+				}
+
+				key, ok := args[1].(object.Hashable)
+				if !ok {
+					return newError("unusable as hash key: %s", args[1].Type())
+				}
+				delete(hash.Pairs, key.HashKey())
+
+				return hash
+			},
+		},
+		"merge": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				pairs := make(map[object.HashKey]object.HashPair)
+				for idx, arg := range args {
+					hash, ok := arg.(*object.Hash)
+					if !ok {
+						return argumentError("merge", idx+1, object.HASH_OBJ, arg)
+					}
+					for key, pair := range hash.Pairs {
+						pairs[key] = pair
+					}
+				}
+
+				return &object.Hash{Pairs: pairs}
+			},
+		},
+	}
+}
+
+// hashArgument checks the argument count and that the first argument is a
+// hash.
+func hashArgument(name string, args []object.Object, count int) (*object.Hash, object.Object) {
+	if len(args) != count {
+		return nil, wrongArgumentCount(len(args), count)
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return nil, argumentError(name, 1, object.HASH_OBJ, args[0])
+	}
+	return hash, nil
+}
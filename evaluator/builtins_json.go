@@ -0,0 +1,193 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// maxIndent is the widest integer indent json_encode accepts, the same limit
+// JavaScript's JSON.stringify applies.
+const maxIndent = 10
+
+// JSON builtins. Hash keys are written in SortedPairs order so the same value
+// always encodes to the same text; non-string keys become their string form,
+// and a hash where two keys share a string form cannot be encoded.
+// Decoded numbers without a fraction or exponent become INTEGERs.
+func jsonBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"json_encode": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+
+				var out bytes.Buffer
+				if err := encodeJSON(&out, args[0]); err != nil {
+					return err
+				}
+				if len(args) == 1 {
+					return &object.String{Value: out.String()}
+				}
+
+				var indent string
+				switch arg := args[1].(type) {
+				case *object.Integer:
+					if arg.Value < 0 {
+						return newError("json_encode indent must not be negative, got %d", arg.Value)
+					}
+					if arg.Value > maxIndent {
+						return newError("json_encode indent must not exceed %d, got %d", maxIndent, arg.Value)
+					}
+					indent = strings.Repeat(" ", int(arg.Value))
+				case *object.String:
+					indent = arg.Value
+				default:
+					return argumentError("json_encode", 2, "INTEGER or STRING", arg)
+				}
+
+				var indented bytes.Buffer
+				if err := json.Indent(&indented, out.Bytes(), "", indent); err != nil {
+					return newError("cannot indent JSON: %s", err)
+				}
+				return &object.String{Value: indented.String()}
+			},
+		},
+		"json_decode": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				strs, err := stringArguments("json_decode", args...)
+				if err != nil {
+					return err
+				}
+
+				dec := json.NewDecoder(strings.NewReader(strs[0]))
+				dec.UseNumber()
+				var value interface{}
+				if err := dec.Decode(&value); err != nil {
+					return newError("invalid JSON: %s", err)
+				}
+				if _, err := dec.Token(); err != io.EOF {
+					return newError("invalid JSON: unexpected data after top-level value")
+				}
+
+				return decodeJSON(value)
+			},
+		},
+	}
+}
+
+func encodeJSON(out *bytes.Buffer, obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Null:
+		out.WriteString("null")
+	case *object.Boolean:
+		out.WriteString(strconv.FormatBool(obj.Value))
+	case *object.Integer:
+		out.WriteString(strconv.FormatInt(obj.Value, 10))
+	case *object.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return newError("cannot encode %s as JSON", strconv.FormatFloat(obj.Value, 'g', -1, 64))
+		}
+		num := strconv.FormatFloat(obj.Value, 'g', -1, 64)
+		if !strings.ContainsAny(num, ".e") {
+			// keep whole floats from decoding back as INTEGERs
+			num += ".0"
+		}
+		out.WriteString(num)
+	case *object.String:
+		encodeJSONString(out, obj.Value)
+	case *object.Array:
+		out.WriteByte('[')
+		for idx, el := range obj.Elements {
+			if idx > 0 {
+				out.WriteByte(',')
+			}
+			if err := encodeJSON(out, el); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case *object.Hash:
+		out.WriteByte('{')
+		seen := make(map[string]bool, len(obj.Pairs))
+		for idx, pair := range obj.SortedPairs() {
+			if idx > 0 {
+				out.WriteByte(',')
+			}
+			key := stringify(pair.Key)
+			if seen[key] {
+				return newError("cannot encode HASH as JSON: duplicate key %q", key)
+			}
+			seen[key] = true
+			encodeJSONString(out, key)
+			out.WriteByte(':')
+			if err := encodeJSON(out, pair.Value); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return newError("cannot encode %s as JSON", obj.Type())
+	}
+	return nil
+}
+
+func encodeJSONString(out *bytes.Buffer, s string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode terminates every value with a newline.
+	out.Truncate(out.Len() - 1)
+}
+
+func decodeJSON(value interface{}) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case string:
+		return &object.String{Value: value}
+	case json.Number:
+		if !strings.ContainsAny(string(value), ".eE") {
+			if n, err := value.Int64(); err == nil {
+				return &object.Integer{Value: n}
+			}
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return newError("invalid JSON: number %s out of range", value)
+		}
+		return &object.Float{Value: f}
+	case []interface{}:
+		elements := make([]object.Object, len(value))
+		for idx, el := range value {
+			elements[idx] = decodeJSON(el)
+			if isError(elements[idx]) {
+				return elements[idx]
+			}
+		}
+		return &object.Array{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[object.HashKey]object.HashPair, len(value))
+		for k, v := range value {
+			key := &object.String{Value: k}
+			val := decodeJSON(v)
+			if isError(val) {
+				return val
+			}
+			pairs[key.HashKey()] = object.HashPair{Key: key, Value: val}
+		}
+		return &object.Hash{Pairs: pairs}
+	default:
+		return newError("invalid JSON: unexpected %T", value)
+	}
+}
@@ -0,0 +1,233 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+var mathConstants = map[string]object.Object{
+	"PI":  &object.Float{Value: math.Pi},
+	"E":   &object.Float{Value: math.E},
+	"INF": &object.Float{Value: math.Inf(1)},
+	"NAN": &object.Float{Value: math.NaN()},
+}
+
+// Math builtins accept INTEGER and FLOAT alike. floor, ceil and round return
+// INTEGERs; the transcendental functions always return FLOATs.
+func mathBuiltins(e *Evaluator) map[string]*object.Builtin {
+	builtins := map[string]*object.Builtin{
+		"abs": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				nums, err := numberArguments("abs", args, 1)
+				if err != nil {
+					return err
+				}
+
+				if integer, ok := args[0].(*object.Integer); ok {
+					if integer.Value == math.MinInt64 {
+						return newError("integer overflow: abs(%d)", integer.Value)
+					}
+					if integer.Value < 0 {
+						return &object.Integer{Value: -integer.Value}
+					}
+					return integer
+				}
+				return &object.Float{Value: math.Abs(nums[0])}
+			},
+		},
+		"floor": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return roundToInteger("floor", args, math.Floor)
+			},
+		},
+		"ceil": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return roundToInteger("ceil", args, math.Ceil)
+			},
+		},
+		"round": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				if len(args) == 1 {
+					return roundToInteger("round", args, math.Round)
+				}
+				nums, err := numberArguments("round", args[:1], 1)
+				if err != nil {
+					return err
+				}
+				digits, ok := args[1].(*object.Integer)
+				if !ok {
+					return argumentError("round", 2, object.INTEGER_OBJ, args[1])
+				}
+
+				// Past float64's range of exponents there is nothing left to
+				// round away, or nothing left at all.
+				scale := math.Pow(10, float64(digits.Value))
+				if math.IsInf(scale, 0) || math.IsInf(nums[0]*scale, 0) {
+					return &object.Float{Value: nums[0]}
+				}
+				if scale == 0 {
+					return &object.Float{Value: math.Copysign(0, nums[0])}
+				}
+				return &object.Float{Value: math.Round(nums[0]*scale) / scale}
+			},
+		},
+		"pow": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if _, err := numberArguments("pow", args, 2); err != nil {
+					return err
+				}
+
+				return evalInfixExpression("**", args[0], args[1])
+			},
+		},
+		"log": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				nums, err := numberArguments("log", args, len(args))
+				if err != nil {
+					return err
+				}
+
+				if len(nums) == 2 {
+					return &object.Float{Value: math.Log(nums[0]) / math.Log(nums[1])}
+				}
+				return &object.Float{Value: math.Log(nums[0])}
+			},
+		},
+		"atan2": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				nums, err := numberArguments("atan2", args, 2)
+				if err != nil {
+					return err
+				}
+
+				return &object.Float{Value: math.Atan2(nums[0], nums[1])}
+			},
+		},
+		"div": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				a, b, err := integerPair("div", args)
+				if err != nil {
+					return err
+				}
+				if b == 0 {
+					return newError("division by zero")
+				}
+
+				q, _ := floorDivMod(a, b)
+				return &object.Integer{Value: q}
+			},
+		},
+		"mod": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				a, b, err := integerPair("mod", args)
+				if err != nil {
+					return err
+				}
+				if b == 0 {
+					return newError("division by zero")
+				}
+
+				_, m := floorDivMod(a, b)
+				return &object.Integer{Value: m}
+			},
+		},
+		"is_nan": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				nums, err := numberArguments("is_nan", args, 1)
+				if err != nil {
+					return err
+				}
+
+				return nativeBoolToBooleanObject(math.IsNaN(nums[0]))
+			},
+		},
+	}
+
+	unary := map[string]func(float64) float64{
+		"sqrt":  math.Sqrt,
+		"exp":   math.Exp,
+		"log2":  math.Log2,
+		"log10": math.Log10,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+		"asin":  math.Asin,
+		"acos":  math.Acos,
+		"atan":  math.Atan,
+	}
+	for name, fn := range unary {
+		name, fn := name, fn
+		builtins[name] = &object.Builtin{
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				nums, err := numberArguments(name, args, 1)
+				if err != nil {
+					return err
+				}
+
+				return &object.Float{Value: fn(nums[0])}
+			},
+		}
+	}
+
+	return builtins
+}
+
+// numberArguments checks the argument count and unwraps INTEGERs and FLOATs
+// as float64.
+func numberArguments(name string, args []object.Object, count int) ([]float64, object.Object) {
+	if len(args) != count {
+		return nil, wrongArgumentCount(len(args), count)
+	}
+	nums := make([]float64, len(args))
+	for idx, arg := range args {
+		if !isNumber(arg) {
+			return nil, argumentError(name, idx+1, "INTEGER or FLOAT", arg)
+		}
+		nums[idx] = toFloat(arg)
+	}
+	return nums, nil
+}
+
+func roundToInteger(name string, args []object.Object, round func(float64) float64) object.Object {
+	nums, err := numberArguments(name, args, 1)
+	if err != nil {
+		return err
+	}
+	if integer, ok := args[0].(*object.Integer); ok {
+		return integer
+	}
+	return floatToInteger(round(nums[0]))
+}
+
+func integerPair(name string, args []object.Object) (int64, int64, object.Object) {
+	if len(args) != 2 {
+		return 0, 0, wrongArgumentCount(len(args), 2)
+	}
+	a, ok := args[0].(*object.Integer)
+	if !ok {
+		return 0, 0, argumentError(name, 1, object.INTEGER_OBJ, args[0])
+	}
+	b, ok := args[1].(*object.Integer)
+	if !ok {
+		return 0, 0, argumentError(name, 2, object.INTEGER_OBJ, args[1])
+	}
+	return a.Value, b.Value, nil
+}
+
+// floorDivMod rounds the quotient toward negative infinity, so the modulus
+// takes the sign of the divisor: div(-7, 2) is -4 and mod(-7, 2) is 1.
+func floorDivMod(a, b int64) (int64, int64) {
+	q, m := a/b, a%b
+	if m != 0 && (m < 0) != (b < 0) {
+		q--
+		m += b
+	}
+	return q, m
+}
@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// Random builtins share the evaluator's source, so seeding it with `seed` or
+// Evaluator.Seed makes every draw that follows deterministic.
+func randomBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"seed": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				seed, ok := args[0].(*object.Integer)
+				if !ok {
+					return argumentError("seed", 1, object.INTEGER_OBJ, args[0])
+				}
+
+				e.Seed(seed.Value)
+				return NULL
+			},
+		},
+		"random": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 0 {
+					return wrongArgumentCount(len(args), 0)
+				}
+
+				return &object.Float{Value: e.rand.Float64()}
+			},
+		},
+		"random_int": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				lo, hi, err := integerPair("random_int", args)
+				if err != nil {
+					return err
+				}
+				if lo > hi {
+					return newError("random_int range is empty: %d > %d", lo, hi)
+				}
+
+				if span := uint64(hi - lo); span < math.MaxInt64 {
+					return &object.Integer{Value: lo + e.rand.Int63n(int64(span)+1)}
+				}
+				// The span overflows int64; any 64-bit draw lands in it at
+				// least half the time.
+				for {
+					if n := int64(e.rand.Uint64()); lo <= n && n <= hi {
+						return &object.Integer{Value: n}
+					}
+				}
+			},
+		},
+		"choice": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				arr, ok := args[0].(*object.Array)
+				if !ok {
+					return argumentError("choice", 1, object.ARRAY_OBJ, args[0])
+				}
+				if len(arr.Elements) == 0 {
+					return NULL
+				}
+
+				return arr.Elements[e.rand.Intn(len(arr.Elements))]
+			},
+		},
+		"shuffle": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				arr, ok := args[0].(*object.Array)
+				if !ok {
+					return argumentError("shuffle", 1, object.ARRAY_OBJ, args[0])
+				}
+
+				elements := make([]object.Object, len(arr.Elements))
+				copy(elements, arr.Elements)
+				e.rand.Shuffle(len(elements), func(i, j int) {
+					elements[i], elements[j] = elements[j], elements[i]
+				})
+
+				return &object.Array{Elements: elements}
+			},
+		},
+	}
+}
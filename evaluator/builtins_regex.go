@@ -0,0 +1,175 @@
+package evaluator
+
+import (
+	"regexp"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// MAX_CACHED_REGEXPS bounds the per-evaluator cache of compiled patterns; the
+// cache starts over once it is full.
+const MAX_CACHED_REGEXPS = 256
+
+// Regex builtins take the pattern first, in Go's RE2 syntax. Captures come
+// back as a hash keyed by group number, plus group name for named groups,
+// with NULL for groups that did not take part in the match.
+func regexBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"regex_match": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_match", args, 2)
+				if err != nil {
+					return err
+				}
+
+				return nativeBoolToBooleanObject(re.MatchString(strs[1]))
+			},
+		},
+		"regex_find": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_find", args, 2)
+				if err != nil {
+					return err
+				}
+
+				loc := re.FindStringIndex(strs[1])
+				if loc == nil {
+					return NULL
+				}
+				return &object.String{Value: strs[1][loc[0]:loc[1]]}
+			},
+		},
+		"regex_find_all": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_find_all", args, 2)
+				if err != nil {
+					return err
+				}
+
+				return stringArray(re.FindAllString(strs[1], -1))
+			},
+		},
+		"regex_captures": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_captures", args, 2)
+				if err != nil {
+					return err
+				}
+
+				match := re.FindStringSubmatchIndex(strs[1])
+				if match == nil {
+					return NULL
+				}
+				return captureHash(re, strs[1], match)
+			},
+		},
+		"regex_captures_all": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_captures_all", args, 2)
+				if err != nil {
+					return err
+				}
+
+				matches := []object.Object{}
+				for _, match := range re.FindAllStringSubmatchIndex(strs[1], -1) {
+					matches = append(matches, captureHash(re, strs[1], match))
+				}
+				return &object.Array{Elements: matches}
+			},
+		},
+		"regex_replace": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 3 {
+					return wrongArgumentCount(len(args), 3)
+				}
+				re, strs, err := e.regexArguments("regex_replace", args[:2], 2)
+				if err != nil {
+					return err
+				}
+
+				switch replacement := args[2].(type) {
+				case *object.String:
+					// $1 and ${name} expand to the matching groups
+					return &object.String{Value: re.ReplaceAllString(strs[1], replacement.Value)}
+				case *object.Function, *object.Builtin:
+					var failed object.Object
+					replaced := re.ReplaceAllStringFunc(strs[1], func(match string) string {
+						if failed != nil {
+							return match
+						}
+						result := ctx.Apply(replacement, &object.String{Value: match})
+						if isError(result) {
+							failed = result
+							return match
+						}
+						return stringify(result)
+					})
+					if failed != nil {
+						return failed
+					}
+					return &object.String{Value: replaced}
+				default:
+					return argumentError("regex_replace", 3, "STRING or FUNCTION", replacement)
+				}
+			},
+		},
+		"regex_split": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				re, strs, err := e.regexArguments("regex_split", args, 2)
+				if err != nil {
+					return err
+				}
+
+				return stringArray(re.Split(strs[1], -1))
+			},
+		},
+	}
+}
+
+// regexArguments checks that args are count strings and compiles the first
+// one as a pattern, reusing an earlier compilation when there is one.
+func (e *Evaluator) regexArguments(name string, args []object.Object, count int) (*regexp.Regexp, []string, object.Object) {
+	if len(args) != count {
+		return nil, nil, wrongArgumentCount(len(args), count)
+	}
+	strs, err := stringArguments(name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if re, ok := e.regexps[strs[0]]; ok {
+		return re, strs, nil
+	}
+	re, compileErr := regexp.Compile(strs[0])
+	if compileErr != nil {
+		return nil, nil, newError("invalid pattern for `%s`: %s", name, compileErr)
+	}
+	if len(e.regexps) >= MAX_CACHED_REGEXPS {
+		e.regexps = make(map[string]*regexp.Regexp)
+	}
+	e.regexps[strs[0]] = re
+
+	return re, strs, nil
+}
+
+// captureHash turns submatch indexes into a hash of group number (and name)
+// to the captured text.
+func captureHash(re *regexp.Regexp, s string, match []int) *object.Hash {
+	pairs := make(map[object.HashKey]object.HashPair)
+	set := func(key object.Object, value object.Object) {
+		pairs[key.(object.Hashable).HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	for idx, name := range re.SubexpNames() {
+		var value object.Object = NULL
+		if match[2*idx] >= 0 {
+			value = &object.String{Value: s[match[2*idx]:match[2*idx+1]]}
+		}
+		set(&object.Integer{Value: int64(idx)}, value)
+		if name != "" {
+			set(&object.String{Value: name}, value)
+		}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
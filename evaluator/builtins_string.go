@@ -0,0 +1,281 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// String builtins count and index in runes, never bytes, so multi-byte
+// characters behave like any other character.
+func stringBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"split": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				strs, err := stringArguments("split", args...)
+				if err != nil {
+					return err
+				}
+
+				var parts []string
+				if len(strs) == 1 {
+					parts = strings.Fields(strs[0])
+				} else {
+					parts = strings.Split(strs[0], strs[1])
+				}
+
+				return stringArray(parts)
+			},
+		},
+		"trim": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				strs, err := stringArguments("trim", args...)
+				if err != nil {
+					return err
+				}
+
+				if len(strs) == 1 {
+					return &object.String{Value: strings.TrimSpace(strs[0])}
+				}
+				return &object.String{Value: strings.Trim(strs[0], strs[1])}
+			},
+		},
+		"upper": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				strs, err := stringArguments("upper", args...)
+				if err != nil {
+					return err
+				}
+
+				return &object.String{Value: strings.ToUpper(strs[0])}
+			},
+		},
+		"lower": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				strs, err := stringArguments("lower", args...)
+				if err != nil {
+					return err
+				}
+
+				return &object.String{Value: strings.ToLower(strs[0])}
+			},
+		},
+		"replace": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 3 && len(args) != 4 {
+					return newError("wrong number of arguments. got=%d, expected=3 or 4", len(args))
+				}
+				strs, err := stringArguments("replace", args[:3]...)
+				if err != nil {
+					return err
+				}
+
+				count := -1
+				if len(args) == 4 {
+					n, ok := args[3].(*object.Integer)
+					if !ok {
+						return argumentError("replace", 4, object.INTEGER_OBJ, args[3])
+					}
+					count = int(n.Value)
+				}
+
+				return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], count)}
+			},
+		},
+		"starts_with": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return wrongArgumentCount(len(args), 2)
+				}
+				strs, err := stringArguments("starts_with", args...)
+				if err != nil {
+					return err
+				}
+
+				return nativeBoolToBooleanObject(strings.HasPrefix(strs[0], strs[1]))
+			},
+		},
+		"ends_with": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return wrongArgumentCount(len(args), 2)
+				}
+				strs, err := stringArguments("ends_with", args...)
+				if err != nil {
+					return err
+				}
+
+				return nativeBoolToBooleanObject(strings.HasSuffix(strs[0], strs[1]))
+			},
+		},
+		"repeat": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return wrongArgumentCount(len(args), 2)
+				}
+				strs, err := stringArguments("repeat", args[0])
+				if err != nil {
+					return err
+				}
+				count, ok := args[1].(*object.Integer)
+				if !ok {
+					return argumentError("repeat", 2, object.INTEGER_OBJ, args[1])
+				}
+				if count.Value < 0 {
+					return newError("repeat count must not be negative, got %d", count.Value)
+				}
+				if len(strs[0]) > 0 && count.Value > int64(maxLength/len(strs[0])) {
+					return newError("repeat result is too long. limit=%d bytes", maxLength)
+				}
+
+				return &object.String{Value: strings.Repeat(strs[0], int(count.Value))}
+			},
+		},
+		"pad_left": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return pad("pad_left", args, true)
+			},
+		},
+		"pad_right": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				return pad("pad_right", args, false)
+			},
+		},
+		"format": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) == 0 {
+					return newError("wrong number of arguments. got=0, expected at least 1")
+				}
+				strs, err := stringArguments("format", args[0])
+				if err != nil {
+					return err
+				}
+
+				formatted, err := formatString(strs[0], args[1:])
+				if err != nil {
+					return err
+				}
+
+				return &object.String{Value: formatted}
+			},
+		},
+		"sprintf": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) == 0 {
+					return newError("wrong number of arguments. got=0, expected at least 1")
+				}
+				strs, err := stringArguments("sprintf", args[0])
+				if err != nil {
+					return err
+				}
+
+				return &object.String{Value: fmt.Sprintf(strs[0], nativeValues(args[1:])...)}
+			},
+		},
+		"chars": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				strs, err := stringArguments("chars", args...)
+				if err != nil {
+					return err
+				}
+
+				chars := []string{}
+				for _, r := range strs[0] {
+					chars = append(chars, string(r))
+				}
+
+				return stringArray(chars)
+			},
+		},
+	}
+}
+
+// stringArguments unwraps args that must all be strings.
+func stringArguments(name string, args ...object.Object) ([]string, object.Object) {
+	strs := make([]string, len(args))
+	for idx, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, argumentError(name, idx+1, object.STRING_OBJ, arg)
+		}
+		strs[idx] = str.Value
+	}
+	return strs, nil
+}
+
+func stringArray(strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for idx, str := range strs {
+		elements[idx] = &object.String{Value: str}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// pad implements pad_left and pad_right: pad(s, width, fill = " ") repeats
+// fill until s is width runes long, cutting the last repetition short.
+func pad(name string, args []object.Object, left bool) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, expected=2 or 3", len(args))
+	}
+	strs, err := stringArguments(name, args[0])
+	if err != nil {
+		return err
+	}
+	width, ok := args[1].(*object.Integer)
+	if !ok {
+		return argumentError(name, 2, object.INTEGER_OBJ, args[1])
+	}
+	fill := " "
+	if len(args) == 3 {
+		f, ok := args[2].(*object.String)
+		if !ok {
+			return argumentError(name, 3, object.STRING_OBJ, args[2])
+		}
+		if f.Value == "" {
+			return newError("padding for `%s` must not be empty", name)
+		}
+		fill = f.Value
+	}
+
+	str := strs[0]
+	length := int64(utf8.RuneCountInString(str))
+	if width.Value <= length {
+		return &object.String{Value: str}
+	}
+	missing := width.Value - length
+	if missing > int64(maxLength/len(fill)) {
+		return newError("%s result is too long. limit=%d bytes", name, maxLength)
+	}
+
+	padding := []rune(strings.Repeat(fill, int(missing)))[:missing]
+	if left {
+		return &object.String{Value: string(padding) + str}
+	}
+	return &object.String{Value: str + string(padding)}
+}
+
+// runeIndex returns the rune offset of sub in s, or -1.
+func runeIndex(s, sub string) int {
+	idx := strings.Index(s, sub)
+	if idx < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:idx])
+}
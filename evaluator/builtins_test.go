@@ -0,0 +1,703 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// errorMessage marks an expected value as the message of an *object.Error,
+// as opposed to a plain string result.
+type errorMessage string
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"map([1, 2, 3], fn(x){ x * 2 })", []interface{}{2, 4, 6}},
+		{"map([], fn(x){ x })", []interface{}{}},
+		{`map(["a", "bc"], len)`, []interface{}{1, 2}},
+		{"map([1], 5)", errorMessage("argument 2 to `map` must be FUNCTION, got INTEGER")},
+		{"map(1, fn(x){ x })", errorMessage("argument 1 to `map` must be ARRAY or HASH, got INTEGER")},
+		{"map([1, true], fn(x){ -x })", errorMessage("unknown operator: -BOOLEAN")},
+		{"filter([1, 2, 3, 4], fn(x){ x % 2 == 0 })", []interface{}{2, 4}},
+		{"filter([1, 2], fn(x){ false })", []interface{}{}},
+		{"reduce([1, 2, 3, 4], fn(acc, x){ acc + x })", 10},
+		{"reduce([1, 2, 3], fn(acc, x){ acc + x }, 10)", 16},
+		{"reduce([], fn(acc, x){ acc + x }, 0)", 0},
+		{"reduce([], fn(acc, x){ acc + x })", errorMessage("reduce of empty array with no initial value")},
+		{"reduce([1])", errorMessage("wrong number of arguments. got=1, expected=2 or 3")},
+		{"sort([3, 1, 2])", []interface{}{1, 2, 3}},
+		{"sort([2.5, 1, 3])", []interface{}{1, 2.5, 3}},
+		{`sort(["pear", "apple"])`, []interface{}{"apple", "pear"}},
+		{"sort([3, 1, 2], fn(a, b){ b - a })", []interface{}{3, 2, 1}},
+		{"sort([3, 1, 2], fn(a, b){ a > b })", []interface{}{3, 2, 1}},
+		{`sort([1, "a"])`, errorMessage("cannot compare STRING with INTEGER")},
+		{`sort([1, 2], fn(a, b){ "x" })`, errorMessage("comparator must return INTEGER or BOOLEAN, got STRING")},
+		{"let twice = fn(f, x){ f(f(x)) }; map([1, 2], fn(x){ twice(fn(y){ y + 1 }, x) })", []interface{}{3, 4}},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`sort_by(["ccc", "a", "bb"], len)`, []interface{}{"a", "bb", "ccc"}},
+		{"sort_by([[2, 1], [1, 2]], first)", []interface{}{[]interface{}{1, 2}, []interface{}{2, 1}}},
+		{"sort_by([1, true], fn(x){ x })", errorMessage("cannot compare BOOLEAN with INTEGER")},
+		{"find([1, 2, 3, 4], fn(x){ x > 2 })", 3},
+		{"find([1, 2], fn(x){ x > 2 })", nil},
+		{"any([1, 2, 3], fn(x){ x > 2 })", true},
+		{"any([], fn(x){ true })", false},
+		{"all([1, 2, 3], fn(x){ x > 0 })", true},
+		{"all([1, 2, 3], fn(x){ x > 1 })", false},
+		{"all(1, fn(x){ x })", errorMessage("argument 1 to `all` must be ARRAY, got INTEGER")},
+		{"reverse([1, 2, 3])", []interface{}{3, 2, 1}},
+		{"reverse([])", []interface{}{}},
+		{"concat([1], [], [2, 3])", []interface{}{1, 2, 3}},
+		{"concat()", []interface{}{}},
+		{"concat([1], 2)", errorMessage("argument 2 to `concat` must be ARRAY, got INTEGER")},
+		{"slice([1, 2, 3, 4], 1)", []interface{}{2, 3, 4}},
+		{"slice([1, 2, 3, 4], 1, 3)", []interface{}{2, 3}},
+		{"slice([1, 2, 3, 4], -2)", []interface{}{3, 4}},
+		{"slice([1, 2, 3, 4], 3, 1)", []interface{}{}},
+		{"slice([1, 2, 3], 0, 99)", []interface{}{1, 2, 3}},
+		{`slice([1, 2], "a")`, errorMessage("argument 2 to `slice` must be INTEGER, got STRING")},
+		{"index_of([1, 2, 3], 2)", 1},
+		{"index_of([1, 2, 3], 2.0)", 1},
+		{"index_of([[1], [2]], [2])", 1},
+		{`index_of(["a"], "b")`, -1},
+		{"contains([1, 2, 3], 3)", true},
+		{`contains([{"a": 1}], {"a": 1})`, true},
+		{"contains([1, 2, 3], 4)", false},
+		{"unique([1, 2, 1, 3, 2])", []interface{}{1, 2, 3}},
+		{`unique(["a", "a", [1], [1]])`, []interface{}{"a", []interface{}{1}}},
+		{"flatten([1, [2, [3, [4]]]])", []interface{}{1, 2, []interface{}{3, []interface{}{4}}}},
+		{"flatten([1, [2, [3, [4]]]], 3)", []interface{}{1, 2, 3, 4}},
+		{"flatten([1, [2]], 0)", []interface{}{1, []interface{}{2}}},
+		{"zip([1, 2, 3], [4, 5])", []interface{}{[]interface{}{1, 4}, []interface{}{2, 5}}},
+		{"zip([1, 2], [3, 4], [5, 6])", []interface{}{[]interface{}{1, 3, 5}, []interface{}{2, 4, 6}}},
+		{"zip()", errorMessage("wrong number of arguments. got=0, expected at least 1")},
+		{"range(3)", []interface{}{0, 1, 2}},
+		{"range(2, 5)", []interface{}{2, 3, 4}},
+		{"range(0, 10, 4)", []interface{}{0, 4, 8}},
+		{"range(3, 0, -1)", []interface{}{3, 2, 1}},
+		{"range(3, 0)", []interface{}{}},
+		{"range(0, 3, 0)", errorMessage("range step must not be zero")},
+		{"range(9223372036854775804, 9223372036854775807, 2)", []interface{}{9223372036854775804, 9223372036854775806}},
+		{"len(range(9223372036854775806, 9223372036854775807, 2))", 1},
+		{"range(-9223372036854775807, 9223372036854775807, 9223372036854775807)", []interface{}{-9223372036854775807, 0}},
+		{"range(3, -9223372036854775807, -9223372036854775807)", []interface{}{3, -9223372036854775804}},
+		{"range(4611686018427387904)", errorMessage("range has too many elements. limit=67108864")},
+		{"range(0, 67108865)", errorMessage("range has too many elements. limit=67108864")},
+		{"range(1.5)", errorMessage("argument 1 to `range` must be INTEGER, got FLOAT")},
+		{`join(["a", "b", "c"], ", ")`, "a, b, c"},
+		{`join([1, "a", true])`, "1atrue"},
+		{`join([], "-")`, ""},
+		{`join(["a"], 1)`, errorMessage("argument 2 to `join` must be STRING, got INTEGER")},
+		{"sum([1, 2, 3])", 6},
+		{"sum([1, 2.5])", 3.5},
+		{"sum([])", 0},
+		{`sum([1, "2"])`, errorMessage("cannot sum STRING")},
+		{"min([3, 1, 2])", 1},
+		{"max([3, 1, 2])", 3},
+		{"min(4, 2.5, 3)", 2.5},
+		{`max("apple", "pear")`, "pear"},
+		{"max([])", nil},
+		{"max()", errorMessage("wrong number of arguments. got=0, expected at least 1")},
+		{"min()", errorMessage("wrong number of arguments. got=0, expected at least 1")},
+		{"min(1)", errorMessage("argument 1 to `min` must be ARRAY, got INTEGER")},
+		{`max([1, "a"])`, errorMessage("cannot compare STRING with INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`keys({"b": 2, "a": 1, 3: 0, true: 4})`, []interface{}{3, "a", "b", true}},
+		{`values({"b": 2, "a": 1})`, []interface{}{1, 2}},
+		{`entries({"b": 2, "a": 1})`, []interface{}{[]interface{}{"a", 1}, []interface{}{"b", 2}}},
+		{"keys({})", []interface{}{}},
+		{"keys([1])", errorMessage("argument 1 to `keys` must be HASH, got ARRAY")},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`has({"a": 1}, [1])`, errorMessage("unusable as hash key: ARRAY")},
+		{`get({"a": 1}, "a")`, 1},
+		{`get({"a": 1}, "b")`, nil},
+		{`get({"a": 1}, "b", 5)`, 5},
+		{`get({"a": 1})`, errorMessage("wrong number of arguments. got=1, expected=2 or 3")},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a"); keys(h)`, []interface{}{"b"}},
+		{`keys(delete({"a": 1}, "z"))`, []interface{}{"a"}},
+		{`let m = merge({"a": 1, "b": 2}, {"b": 3}, {"c": 4}); values(m)`, []interface{}{1, 3, 4}},
+		{`let a = {"a": 1}; merge(a, {"b": 2}); len(a)`, 1},
+		{`merge({}, 1)`, errorMessage("argument 2 to `merge` must be HASH, got INTEGER")},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`map({"b": 2, "a": 1}, fn(k, v){ [k, v * 10] })`, []interface{}{[]interface{}{"a", 10}, []interface{}{"b", 20}}},
+		{`keys(filter({"a": 1, "b": 2, "c": 3}, fn(k, v){ v != 2 }))`, []interface{}{"a", "c"}},
+		{"each([1, 2], fn(x){ x })", nil},
+		{`each({"a": 1}, fn(k, v){ k - v })`, errorMessage("type mismatch: STRING - INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("héllo")`, 5},
+		{`split("a,b,,c", ",")`, []interface{}{"a", "b", "", "c"}},
+		{`split("  monkey   see  ")`, []interface{}{"monkey", "see"}},
+		{`split("añb", "")`, []interface{}{"a", "ñ", "b"}},
+		{`split(1, ",")`, errorMessage("argument 1 to `split` must be STRING, got INTEGER")},
+		{`join(split("a b c"), "-")`, "a-b-c"},
+		{`trim("  paw ")`, "paw"},
+		{`trim("--paw--", "-")`, "paw"},
+		{`upper("mönkey")`, "MÖNKEY"},
+		{`lower("MÖNKEY")`, "mönkey"},
+		{`replace("banana", "a", "o")`, "bonono"},
+		{`replace("banana", "a", "o", 1)`, "bonana"},
+		{`replace("banana", "a")`, errorMessage("wrong number of arguments. got=2, expected=3 or 4")},
+		{`starts_with("monkey", "mon")`, true},
+		{`starts_with("monkey", "key")`, false},
+		{`ends_with("monkey", "key")`, true},
+		{`contains("monkey", "nk")`, true},
+		{`contains("monkey", "x")`, false},
+		{`contains("monkey", 1)`, errorMessage("argument 2 to `contains` must be STRING, got INTEGER")},
+		{`contains(1, 1)`, errorMessage("argument 1 to `contains` must be ARRAY or STRING, got INTEGER")},
+		{`index_of("héllo", "llo")`, 2},
+		{`index_of("hello", "z")`, -1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("ab", -1)`, errorMessage("repeat count must not be negative, got -1")},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_left("né", 4)`, "  né"},
+		{`pad_right("ab", 5, "xy")`, "abxyx"},
+		{`pad_right("abcdef", 3)`, "abcdef"},
+		{`pad_left("a", 3, "")`, errorMessage("padding for `pad_left` must not be empty")},
+		{`repeat("ab", 9223372036854775807)`, errorMessage("repeat result is too long. limit=67108864 bytes")},
+		{`repeat("ab", 33554433)`, errorMessage("repeat result is too long. limit=67108864 bytes")},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`pad_left("a", 4611686018427387904)`, errorMessage("pad_left result is too long. limit=67108864 bytes")},
+		{`pad_left("a", 9223372036854775807, "ab")`, errorMessage("pad_left result is too long. limit=67108864 bytes")},
+		{`pad_right("a", 9223372036854775807)`, errorMessage("pad_right result is too long. limit=67108864 bytes")},
+		{`pad_right("a", -9223372036854775807)`, "a"},
+		{`chars("añb")`, []interface{}{"a", "ñ", "b"}},
+		{`chars("")`, []interface{}{}},
+		{`"apple" < "banana"`, true},
+		{`"apple" > "banana"`, false},
+		{`"ä" > "z"`, true},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFormatBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`format("{} is {:.2f}", "pi", 3.14159)`, "pi is 3.14"},
+		{`format("{1}-{0}-{1}", "a", "b")`, "b-a-b"},
+		{`format("{{}} {}", [1, 2])`, "{} [1, 2]"},
+		{`format("[{:5}] [{:<5}]", "ab", "ab")`, "[   ab] [ab   ]"},
+		{`format("{:03d} {:x} {:b}", 7, 255, 5)`, "007 ff 101"},
+		{`format("{:.1f} {:.3}", 2, 1.5)`, "2.0 1.500"},
+		{`format("{:e}", 1234.5)`, "1.234500e+03"},
+		{`format("no placeholders")`, "no placeholders"},
+		{`format("{} {}", 1)`, errorMessage("format needs argument 2, got 1 arguments")},
+		{`format("{:d}", 1.5)`, errorMessage("format spec {:d} needs INTEGER, got FLOAT")},
+		{`format("{:f}", "a")`, errorMessage("format spec {:f} needs a number, got STRING")},
+		{`format("{:q}", 1)`, errorMessage("unknown format type \"q\" in {:q}")},
+		{`format("{:.f}", 1)`, errorMessage("invalid format spec \".f\"")},
+		{`len(format("{:1000d}{:.1000f}", 1, 1))`, 2002},
+		{`format("{:99999999999d}", 1)`, errorMessage("format width in {:99999999999d} must not exceed 1000")},
+		{`format("{:1001}", "a")`, errorMessage("format width in {:1001} must not exceed 1000")},
+		{`format("{:.1001f}", 1)`, errorMessage("format precision in {:.1001f} must not exceed 1000")},
+		{`format("{:.99999999999999999999}", "a")`, errorMessage("format precision in {:.99999999999999999999} must not exceed 1000")},
+		{`format("{x}", 1)`, errorMessage("invalid placeholder {x}")},
+		{`format("{", 1)`, errorMessage("unclosed { in format string")},
+		{`format("}", 1)`, errorMessage("unmatched } in format string")},
+		{`format(1)`, errorMessage("argument 1 to `format` must be STRING, got INTEGER")},
+		{`sprintf("%d-%s-%.1f-%t", 1, "a", 2.25, true)`, "1-a-2.2-true"},
+		{`sprintf("%v", [1])`, "[1]"},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(1)`, "INTEGER"},
+		{`type(1.5)`, "FLOAT"},
+		{`type("a")`, "STRING"},
+		{`type([1])`, "ARRAY"},
+		{`type({})`, "HASH"},
+		{`type(fn(x){ x })`, "FUNCTION"},
+		{`type(len)`, "BUILTIN"},
+		{`type(if (false) { 1 })`, "NULL"},
+		{`int(" 42 ")`, 42},
+		{`int("-7")`, -7},
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`int(true)`, 1},
+		{`int("4.2")`, errorMessage(`cannot convert "4.2" to INTEGER`)},
+		{`int("abc")`, errorMessage(`cannot convert "abc" to INTEGER`)},
+		{`int([1])`, errorMessage("argument 1 to `int` must be INTEGER, FLOAT, STRING or BOOLEAN, got ARRAY")},
+		{`float(2)`, 2.0},
+		{`float("2.5")`, 2.5},
+		{`float("1e3")`, 1000.0},
+		{`float("x")`, errorMessage(`cannot convert "x" to FLOAT`)},
+		{`float(true)`, errorMessage("argument 1 to `float` must be INTEGER, FLOAT or STRING, got BOOLEAN")},
+		{`str(12)`, "12"},
+		{`str("a")`, "a"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`bool("true")`, true},
+		{`bool("false")`, false},
+		{`bool("yes")`, errorMessage(`cannot convert "yes" to BOOLEAN`)},
+		{`bool(0)`, true},
+		{`bool(if (false) { 1 })`, false},
+		{`is_int(1)`, true},
+		{`is_int(1.0)`, false},
+		{`is_float(1.0)`, true},
+		{`is_number(1)`, true},
+		{`is_number("1")`, false},
+		{`is_string("1")`, true},
+		{`is_bool(false)`, true},
+		{`is_array([])`, true},
+		{`is_hash({})`, true},
+		{`is_null(if (false) { 1 })`, true},
+		{`is_function(len)`, true},
+		{`is_function(fn(){ 1 })`, true},
+		{`is_function(1)`, false},
+		{`is_int()`, errorMessage("wrong number of arguments. got=0, expected=1")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"abs(-3)", 3},
+		{"abs(-2.5)", 2.5},
+		{"abs(-9223372036854775807 - 1)", errorMessage("integer overflow: abs(-9223372036854775808)")},
+		{"abs(-9223372036854775807)", 9223372036854775807},
+		{"2 ** 62", 4611686018427387904},
+		{"2 ** 63", errorMessage("integer overflow: 2 ** 63")},
+		{"2 ** 64", errorMessage("integer overflow: 2 ** 64")},
+		{"(-2) ** 63", -9223372036854775808},
+		{"3 ** 40", errorMessage("integer overflow: 3 ** 40")},
+		{"(-1) ** 9223372036854775807", -1},
+		{"pow(10, 19)", errorMessage("integer overflow: 10 ** 19")},
+		{"floor(2.7)", 2},
+		{"floor(-2.5)", -3},
+		{"ceil(2.1)", 3},
+		{"ceil(4)", 4},
+		{"round(2.5)", 3},
+		{"round(-2.5)", -3},
+		{"round(3.14159, 2)", 3.14},
+		{"round(1.5, 400)", 1.5},
+		{"round(1.5, 9223372036854775807)", 1.5},
+		{"round(123.5, -1)", 120.0},
+		{"round(1.5, -400)", 0.0},
+		{"round(1.5 * 10.0 ** 300, 10) == 1.5 * 10.0 ** 300", true},
+		{"round(INF, 2) == INF", true},
+		{"floor(INF)", errorMessage("cannot convert +Inf to INTEGER")},
+		{"sqrt(16)", 4.0},
+		{"pow(2, 8)", 256},
+		{"pow(4, 0.5)", 2.0},
+		{"exp(0)", 1.0},
+		{"log(E)", 1.0},
+		{"log(8, 2)", 3.0},
+		{"log2(8)", 3.0},
+		{"log10(1000)", 3.0},
+		{"sin(0)", 0.0},
+		{"cos(0)", 1.0},
+		{"round(atan2(1, 1) * 4, 5)", 3.14159},
+		{"round(PI, 2)", 3.14},
+		{"INF > 1000000", true},
+		{"is_nan(NAN)", true},
+		{"NAN == NAN", false},
+		{"is_nan(1)", false},
+		{"div(7, 2)", 3},
+		{"div(-7, 2)", -4},
+		{"mod(-7, 2)", 1},
+		{"mod(7, -2)", -1},
+		{"div(1, 0)", errorMessage("division by zero")},
+		{"mod(1.5, 2)", errorMessage("argument 1 to `mod` must be INTEGER, got FLOAT")},
+		{`sqrt("4")`, errorMessage("argument 1 to `sqrt` must be INTEGER or FLOAT, got STRING")},
+		{"sqrt()", errorMessage("wrong number of arguments. got=0, expected=1")},
+		{"min(3, 1, 2)", 1},
+		{"max([1.5, 4])", 4},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRandomBuiltins(t *testing.T) {
+	e := New(FULL_PROFILE)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"all(map(range(100), fn(i){ random() }), fn(r){ if (r < 0) { false } else { r < 1 } })", true},
+		{"all(map(range(100), fn(i){ random_int(-2, 2) }), fn(n){ if (n < -2) { false } else { n < 3 } })", true},
+		{"random_int(3, 3)", 3},
+		{"random_int(3, 2)", errorMessage("random_int range is empty: 3 > 2")},
+		{"random_int(1, 2.5)", errorMessage("argument 2 to `random_int` must be INTEGER, got FLOAT")},
+		{"contains([1, 2, 3], choice([1, 2, 3]))", true},
+		{"choice([])", nil},
+		{"sort(shuffle([3, 1, 2, 5, 4]))", []interface{}{1, 2, 3, 4, 5}},
+		{"let a = [1, 2, 3]; shuffle(a); a", []interface{}{1, 2, 3}},
+		{"seed(1.5)", errorMessage("argument 1 to `seed` must be INTEGER, got FLOAT")},
+		{"random(1)", errorMessage("wrong number of arguments. got=1, expected=0")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	draws := `[random(), random_int(0, 1000000), choice(range(100)), shuffle(range(10))]`
+
+	first, second := New(FULL_PROFILE), New(FULL_PROFILE)
+	first.Seed(42)
+	second.Seed(42)
+	want := testEvalWith(first, draws).Inspect()
+	if got := testEvalWith(second, draws).Inspect(); got != want {
+		t.Errorf("Seed(42) draws differ. first=%s, second=%s", want, got)
+	}
+
+	scripted := New(FULL_PROFILE)
+	if got := testEvalWith(scripted, "seed(42); "+draws).Inspect(); got != want {
+		t.Errorf("seed(42) draws differ from Seed(42). expected=%s, got=%s", want, got)
+	}
+}
+
+func TestJSONBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`json_encode({"b": [1, 2.5, true], "a": "x<y", 3: if (false) { 1 }})`, `{"3":null,"a":"x<y","b":[1,2.5,true]}`},
+		{`json_encode(2.0)`, "2.0"},
+		{"json_encode(`say \"hi\"`)", `"say \"hi\""`},
+		{`json_encode([])`, "[]"},
+		{`json_encode({})`, "{}"},
+		{`json_encode({"a": [1, 2]}, 2)`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{`json_encode([1], "--")`, "[\n--1\n]"},
+		{`json_encode([1], 10)`, "[\n          1\n]"},
+		{`json_encode([1], -1)`, errorMessage("json_encode indent must not be negative, got -1")},
+		{`json_encode([1], 11)`, errorMessage("json_encode indent must not exceed 10, got 11")},
+		{`json_encode([1], 9223372036854775807)`, errorMessage("json_encode indent must not exceed 10, got 9223372036854775807")},
+		{`json_encode({1: "a", "1": "b"})`, errorMessage(`cannot encode HASH as JSON: duplicate key "1"`)},
+		{`json_encode([{true: 1, "true": 2}])`, errorMessage(`cannot encode HASH as JSON: duplicate key "true"`)},
+		{`json_encode({1: "a", "2": "b"})`, `{"1":"a","2":"b"}`},
+		{`json_encode([len])`, errorMessage("cannot encode BUILTIN as JSON")},
+		{`json_encode({"f": fn(x){ x }})`, errorMessage("cannot encode FUNCTION as JSON")},
+		{`json_encode(NAN)`, errorMessage("cannot encode NaN as JSON")},
+		{`json_encode(1, true)`, errorMessage("argument 2 to `json_encode` must be INTEGER or STRING, got BOOLEAN")},
+		{`json_decode("42")`, 42},
+		{`json_decode("-1.5e2")`, -150.0},
+		{`json_decode("2.0")`, 2.0},
+		{`json_decode("null")`, nil},
+		{"json_decode(`[1, \"a\", false]`)", []interface{}{1, "a", false}},
+		{"json_decode(`{\"a\": {\"b\": [1]}}`)[\"a\"][\"b\"]", []interface{}{1}},
+		{`json_decode("123456789012345678901234567890")`, 123456789012345678901234567890.0},
+		{`json_decode(json_encode({"k": [1, 2.5, "s", true]}))["k"]`, []interface{}{1, 2.5, "s", true}},
+		{`json_decode("{")`, errorMessage("invalid JSON: unexpected EOF")},
+		{`json_decode("[1] [2]")`, errorMessage("invalid JSON: unexpected data after top-level value")},
+		{`json_decode(1)`, errorMessage("argument 1 to `json_decode` must be STRING, got INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFSBuiltins(t *testing.T) {
+	e := New(FULL_PROFILE)
+	e.SetFileSystem(NewMemFS(map[string]string{
+		"in.txt":       "monkey",
+		"data/a.json":  "[1]",
+		"data/b/c.txt": "deep",
+	}))
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`read_file("in.txt")`, "monkey"},
+		{`json_decode(read_file("data/a.json"))`, []interface{}{1}},
+		{`read_file("missing.txt")`, errorMessage(`read_file "missing.txt": file does not exist`)},
+		{`read_file("../in.txt")`, errorMessage(`read_file "../in.txt": invalid argument`)},
+		{`read_file(1)`, errorMessage("argument 1 to `read_file` must be STRING, got INTEGER")},
+		{`list_dir()`, []interface{}{"data", "in.txt"}},
+		{`list_dir("data")`, []interface{}{"a.json", "b"}},
+		{`list_dir("nope")`, errorMessage(`list_dir "nope": file does not exist`)},
+		{`exists("data/b")`, true},
+		{`exists("out.txt")`, false},
+		{`write_file("out.txt", "a")`, nil},
+		{`append_file("out.txt", "b"); append_file("log.txt", "x"); read_file("out.txt") + read_file("log.txt")`, "abx"},
+		{`write_file("data", "x")`, errorMessage(`write_file "data": file already exists`)},
+		{`write_file("in.txt/x", "x")`, errorMessage(`write_file "in.txt/x": invalid argument`)},
+		{`remove("out.txt"); exists("out.txt")`, false},
+		{`remove("out.txt")`, errorMessage(`remove "out.txt": file does not exist`)},
+		{`remove(".")`, errorMessage(`remove ".": invalid argument`)},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+}
+
+func TestFSBuiltinsWithoutFileSystem(t *testing.T) {
+	testExpected(t, `read_file("x")`, testEvalWith(New(FULL_PROFILE), `read_file("x")`),
+		errorMessage("`read_file` needs a file system, but none is configured"))
+	testExpected(t, `read_file("x")`, testEvalWith(New(STDIO_PROFILE), `read_file("x")`),
+		errorMessage("identifier not found: read_file"))
+}
+
+func TestMemFS(t *testing.T) {
+	fsys := NewMemFS(map[string]string{
+		"a.txt":     "a",
+		"dir/b.txt": "bb",
+		"dir/s/c":   "",
+	})
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/s/c"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "in.txt"), []byte("paw"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("s"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if err := os.Symlink(".", filepath.Join(dir, "inner")); err != nil {
+		t.Fatal(err)
+	}
+	e := New(FULL_PROFILE)
+	e.SetFileSystem(DirFS(dir))
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`read_file("in.txt")`, "paw"},
+		{`write_file("out.txt", upper(read_file("in.txt"))); append_file("out.txt", "!")`, nil},
+		{`list_dir()`, []interface{}{"in.txt", "inner", "link", "out.txt"}},
+		{`read_file("nope.txt")`, errorMessage(`read_file "nope.txt": file does not exist`)},
+		{`write_file("../escape.txt", "x")`, errorMessage(`write_file "../escape.txt": invalid argument`)},
+		{`read_file("link/secret.txt")`, errorMessage(`read_file "link/secret.txt": path escapes from the file system root`)},
+		{`write_file("link/new.txt", "x")`, errorMessage(`write_file "link/new.txt": path escapes from the file system root`)},
+		{`list_dir("link")`, errorMessage(`list_dir "link": path escapes from the file system root`)},
+		{`read_file("inner/in.txt")`, "paw"},
+		{`remove(".")`, errorMessage(`remove ".": invalid argument`)},
+		{`remove("in.txt"); exists("in.txt")`, false},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil || string(data) != "PAW!" {
+		t.Errorf("out.txt wrong. expected=%q, got=%q (%v)", "PAW!", data, err)
+	}
+}
+
+func TestTimeBuiltins(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC))
+	e := New(FULL_PROFILE)
+	e.SetClock(clock)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(now())`, "TIME"},
+		{`format_time(now())`, "2024-03-01T12:30:00Z"},
+		{`format_time(now(), "DateOnly")`, "2024-03-01"},
+		{`format_time(now(), "Jan 2, 15:04")`, "Mar 1, 12:30"},
+		{`unix_time()`, 1709296200},
+		{`unix_time(parse_time("1970-01-02", "DateOnly"))`, 86400},
+		{`let start = now(); sleep(1500); str(now() - start)`, "1.5s"},
+		{`sleep(duration("2m")); format_time(now(), "TimeOnly")`, "12:32:01"},
+		{`sleep(-1)`, errorMessage("sleep duration must not be negative, got -1ms")},
+		{`sleep("1s")`, errorMessage("argument 1 to `sleep` must be DURATION or INTEGER, got STRING")},
+		{`sleep(9223372036854775807)`, errorMessage("duration overflow: 9223372036854775807ms")},
+		{`str(duration(9223372036854))`, "2562047h47m16.854s"},
+		{`duration(9223372036855)`, errorMessage("duration overflow: 9223372036855ms")},
+		{`duration(-9223372036855)`, errorMessage("duration overflow: -9223372036855ms")},
+		{`duration("1h") * 9223372036854775807`, errorMessage("duration overflow: 1h0m0s * 9223372036854775807")},
+		{`9223372036854775807 * duration("1h")`, errorMessage("duration overflow: 9223372036854775807 * 1h0m0s")},
+		{`duration("1s") / 0.0000000001`, errorMessage("duration overflow: 1s / 0.000000")},
+		{`duration("1s") * NAN`, errorMessage("duration overflow: 1s * NaN")},
+		{`str(duration("1h30m"))`, "1h30m0s"},
+		{`str(duration(250))`, "250ms"},
+		{`duration("soon")`, errorMessage(`cannot parse "soon" as duration`)},
+		{`duration_ms(duration("2s") * 3 + duration(5))`, 6005},
+		{`duration_ms(2 * duration("1s") / 4)`, 500},
+		{`duration("1h") / duration("15m")`, 4.0},
+		{`str(-duration("1s"))`, "-1s"},
+		{`format_time(parse_time("2024-01-31", "DateOnly") + duration("24h"), "DateOnly")`, "2024-02-01"},
+		{`parse_time("2024-01-31", "DateOnly") < parse_time("2024-02-01", "DateOnly")`, true},
+		{`parse_time("2024-01-31T00:00:00Z") == parse_time("2024-01-31", "DateOnly")`, true},
+		{`duration("1s") > duration(999)`, true},
+		{`map(sort([duration("1m"), duration("1s")]), str)`, []interface{}{"1s", "1m0s"}},
+		{`parse_time("31/01/2024", "DateOnly")`, errorMessage(`cannot parse "31/01/2024" as time with layout "2006-01-02"`)},
+		{`now() + 1`, errorMessage("type mismatch: TIME + INTEGER")},
+		{`now() * now()`, errorMessage("unknown operator: TIME * TIME")},
+		{`duration("1s") / 0`, errorMessage("division by zero")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+
+	if got := testEvalWith(New(STDIO_PROFILE), "now()"); !isError(got) {
+		t.Errorf("now() without CLOCK should fail, got=%s", got.Inspect())
+	}
+}
+
+func TestRegexBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`regex_match("^m.+y$", "monkey")`, true},
+		{`regex_match("^\d+$", "12a")`, false},
+		{`regex_find("[0-9]+", "paw 42 and 7")`, "42"},
+		{`regex_find("[0-9]+", "none")`, nil},
+		{`regex_find_all("[0-9]+", "paw 42 and 7")`, []interface{}{"42", "7"}},
+		{`regex_find_all("x", "none")`, []interface{}{}},
+		{`regex_captures("(\w+)@(\w+)", "mail momo@jungle now")[2]`, "jungle"},
+		{`regex_captures("(?P<key>\w+)=(?P<value>\w*)", "a=1")["value"]`, "1"},
+		{`regex_captures("(a)|(b)", "b")[1]`, nil},
+		{`regex_captures("z", "abc")`, nil},
+		{`map(regex_captures_all("(\w)=(\d)", "a=1 b=2"), fn(m){ m[1] + m[2] })`, []interface{}{"a1", "b2"}},
+		{`regex_replace("(\w+)@(\w+)", "momo@jungle", "$2:$1")`, "jungle:momo"},
+		{`regex_replace("[0-9]+", "3 apples, 12 pears", fn(n){ int(n) * 2 })`, "6 apples, 24 pears"},
+		{`regex_replace("a", "banana", upper)`, "bAnAnA"},
+		{`regex_replace("a", "banana", fn(x){ 1 + true })`, errorMessage("type mismatch: INTEGER + BOOLEAN")},
+		{`regex_replace("a", "banana", 1)`, errorMessage("argument 3 to `regex_replace` must be STRING or FUNCTION, got INTEGER")},
+		{`regex_split("\s*,\s*", "a , b,c")`, []interface{}{"a", "b", "c"}},
+		{`regex_match("(a", "a")`, errorMessage("invalid pattern for `regex_match`: error parsing regexp: missing closing ): `(a`")},
+		{`regex_match(1, "a")`, errorMessage("argument 1 to `regex_match` must be STRING, got INTEGER")},
+		{`regex_split("a")`, errorMessage("wrong number of arguments. got=1, expected=2")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	e := New(PURE_PROFILE)
+	testEvalWith(e, `regex_match("a+", "aa"); regex_find("a+", "baa"); regex_match("b", "b")`)
+	if len(e.regexps) != 2 {
+		t.Errorf("expected 2 cached patterns, got=%d", len(e.regexps))
+	}
+	testEvalWith(e, `regex_match("(", "")`)
+	if len(e.regexps) != 2 {
+		t.Errorf("invalid pattern should not be cached, got=%d patterns", len(e.regexps))
+	}
+}
+
+// testExpected compares evaluated against a Go value: ints, floats, bools,
+// strings, nil for NULL, slices for arrays and errorMessage for errors.
+func testExpected(t *testing.T, input string, evaluated object.Object, expected interface{}) bool {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, evaluated, int64(expected))
+	case float64:
+		return testFloatObject(t, evaluated, expected)
+	case bool:
+		return testBooleanObject(t, evaluated, expected)
+	case nil:
+		return testNullObject(t, evaluated)
+	case string:
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: object is not String. got=%T (%+v)", input, evaluated, evaluated)
+			return false
+		}
+		if str.Value != expected {
+			t.Errorf("%s: String has wrong value. expected=%q, got=%q", input, expected, str.Value)
+			return false
+		}
+	case errorMessage:
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error. got=%T (%+v)", input, evaluated, evaluated)
+			return false
+		}
+		if errObj.Message != string(expected) {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", input, expected, errObj.Message)
+			return false
+		}
+	case []interface{}:
+		arr, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array. got=%T (%+v)", input, evaluated, evaluated)
+			return false
+		}
+		if len(arr.Elements) != len(expected) {
+			t.Errorf("%s: wrong num of elements. expected=%d, got=%d (%s)", input, len(expected), len(arr.Elements), arr.Inspect())
+			return false
+		}
+		for idx, el := range expected {
+			if !testExpected(t, input, arr.Elements[idx], el) {
+				return false
+			}
+		}
+	default:
+		t.Fatalf("%s: unsupported expected type %T", input, expected)
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"math"
+	"time"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// timeLayouts are the layout names format_time and parse_time accept besides
+// a Go reference layout such as "2006-01-02 15:04".
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"Kitchen":     time.Kitchen,
+}
+
+// Time builtins read the evaluator's Clock (see SetClock). TIME and DURATION
+// values combine with the arithmetic and comparison operators: a TIME minus a
+// TIME is a DURATION, and DURATIONs can be added to TIMEs, added to each
+// other and scaled by numbers.
+func timeBuiltins(e *Evaluator) map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"now": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 0 {
+					return wrongArgumentCount(len(args), 0)
+				}
+
+				return &object.Time{Value: e.clock.Now()}
+			},
+		},
+		"unix_time": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) > 1 {
+					return newError("wrong number of arguments. got=%d, expected=0 or 1", len(args))
+				}
+				if len(args) == 0 {
+					return &object.Integer{Value: e.clock.Now().Unix()}
+				}
+				t, ok := args[0].(*object.Time)
+				if !ok {
+					return argumentError("unix_time", 1, object.TIME_OBJ, args[0])
+				}
+
+				return &object.Integer{Value: t.Value.Unix()}
+			},
+		},
+		"sleep": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				d, err := durationArgument("sleep", args[0])
+				if err != nil {
+					return err
+				}
+				if d < 0 {
+					return newError("sleep duration must not be negative, got %s", d)
+				}
+
+				e.clock.Sleep(d)
+				return NULL
+			},
+		},
+		"format_time": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				t, ok := args[0].(*object.Time)
+				if !ok {
+					return argumentError("format_time", 1, object.TIME_OBJ, args[0])
+				}
+				layout, err := layoutArgument("format_time", args[1:])
+				if err != nil {
+					return err
+				}
+
+				return &object.String{Value: t.Value.Format(layout)}
+			},
+		},
+		"parse_time": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 && len(args) != 2 {
+					return newError("wrong number of arguments. got=%d, expected=1 or 2", len(args))
+				}
+				strs, err := stringArguments("parse_time", args[0])
+				if err != nil {
+					return err
+				}
+				layout, err := layoutArgument("parse_time", args[1:])
+				if err != nil {
+					return err
+				}
+
+				t, parseErr := time.Parse(layout, strs[0])
+				if parseErr != nil {
+					return newError("cannot parse %q as time with layout %q", strs[0], layout)
+				}
+
+				return &object.Time{Value: t}
+			},
+		},
+		"duration": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				if str, ok := args[0].(*object.String); ok {
+					d, err := time.ParseDuration(str.Value)
+					if err != nil {
+						return newError("cannot parse %q as duration", str.Value)
+					}
+					return &object.Duration{Value: d}
+				}
+				d, err := durationArgument("duration", args[0])
+				if err != nil {
+					return err
+				}
+
+				return &object.Duration{Value: d}
+			},
+		},
+		"duration_ms": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+				d, ok := args[0].(*object.Duration)
+				if !ok {
+					return argumentError("duration_ms", 1, object.DURATION_OBJ, args[0])
+				}
+
+				return &object.Integer{Value: d.Value.Milliseconds()}
+			},
+		},
+	}
+}
+
+// durationArgument accepts a DURATION or an INTEGER number of milliseconds.
+func durationArgument(name string, arg object.Object) (time.Duration, object.Object) {
+	switch arg := arg.(type) {
+	case *object.Duration:
+		return arg.Value, nil
+	case *object.Integer:
+		if arg.Value > math.MaxInt64/int64(time.Millisecond) || arg.Value < math.MinInt64/int64(time.Millisecond) {
+			return 0, newError("duration overflow: %dms", arg.Value)
+		}
+		return time.Duration(arg.Value) * time.Millisecond, nil
+	default:
+		return 0, argumentError(name, 1, "DURATION or INTEGER", arg)
+	}
+}
+
+// layoutArgument resolves an optional layout argument, defaulting to RFC3339.
+func layoutArgument(name string, args []object.Object) (string, object.Object) {
+	if len(args) == 0 {
+		return time.RFC3339, nil
+	}
+	layout, ok := args[0].(*object.String)
+	if !ok {
+		return "", argumentError(name, 2, object.STRING_OBJ, args[0])
+	}
+	if named, ok := timeLayouts[layout.Value]; ok {
+		return named, nil
+	}
+	return layout.Value, nil
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// Type builtins let scripts inspect and convert values they did not create,
+// such as ones handed over by the host. Conversions from strings parse the
+// trimmed text and fail with an error instead of guessing.
+func typeBuiltins(e *Evaluator) map[string]*object.Builtin {
+	builtins := map[string]*object.Builtin{
+		"type": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				return &object.String{Value: string(args[0].Type())}
+			},
+		},
+		"int": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				switch arg := args[0].(type) {
+				case *object.Integer:
+					return arg
+				case *object.Float:
+					return floatToInteger(math.Trunc(arg.Value))
+				case *object.String:
+					value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+					if err != nil {
+						return newError("cannot convert %q to INTEGER", arg.Value)
+					}
+					return &object.Integer{Value: value}
+				case *object.Boolean:
+					if arg.Value {
+						return &object.Integer{Value: 1}
+					}
+					return &object.Integer{Value: 0}
+				default:
+					return argumentError("int", 1, "INTEGER, FLOAT, STRING or BOOLEAN", arg)
+				}
+			},
+		},
+		"float": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				switch arg := args[0].(type) {
+				case *object.Integer:
+					return &object.Float{Value: float64(arg.Value)}
+				case *object.Float:
+					return arg
+				case *object.String:
+					value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+					if err != nil {
+						return newError("cannot convert %q to FLOAT", arg.Value)
+					}
+					return &object.Float{Value: value}
+				default:
+					return argumentError("float", 1, "INTEGER, FLOAT or STRING", arg)
+				}
+			},
+		},
+		"methods": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				return stringArray(e.Methods(args[0].Type()))
+			},
+		},
+		"str": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				return &object.String{Value: stringify(args[0])}
+			},
+		},
+		"bool": {
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				str, ok := args[0].(*object.String)
+				if !ok {
+					return nativeBoolToBooleanObject(isTruthy(args[0]))
+				}
+				switch strings.TrimSpace(str.Value) {
+				case "true":
+					return TRUE
+				case "false":
+					return FALSE
+				default:
+					return newError("cannot convert %q to BOOLEAN", str.Value)
+				}
+			},
+		},
+	}
+
+	checks := map[string]func(obj object.Object) bool{
+		"is_int":      func(obj object.Object) bool { return obj.Type() == object.INTEGER_OBJ },
+		"is_float":    func(obj object.Object) bool { return obj.Type() == object.FLOAT_OBJ },
+		"is_number":   isNumber,
+		"is_string":   func(obj object.Object) bool { return obj.Type() == object.STRING_OBJ },
+		"is_bool":     func(obj object.Object) bool { return obj.Type() == object.BOOLEAN_OBJ },
+		"is_array":    func(obj object.Object) bool { return obj.Type() == object.ARRAY_OBJ },
+		"is_hash":     func(obj object.Object) bool { return obj.Type() == object.HASH_OBJ },
+		"is_null":     func(obj object.Object) bool { return obj.Type() == object.NULL_OBJ },
+		"is_function": isCallable,
+	}
+	for name, check := range checks {
+		check := check
+		builtins[name] = &object.Builtin{
+			Fn: func(ctx object.Context, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return wrongArgumentCount(len(args), 1)
+				}
+
+				return nativeBoolToBooleanObject(check(args[0]))
+			},
+		}
+	}
+
+	return builtins
+}
+
+// floatToInteger converts a whole-valued float, failing for NaN, infinities
+// and values outside the INTEGER range.
+func floatToInteger(value float64) object.Object {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value >= math.MaxInt64 || value < math.MinInt64 {
+		return newError("cannot convert %s to INTEGER", strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	return &object.Integer{Value: int64(value)}
+}
@@ -0,0 +1,39 @@
+package evaluator
+
+import "time"
+
+// Clock is where the time builtins get the current time from and how `sleep`
+// waits, so tests can swap the wall clock for a FakeClock.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SetClock replaces the clock behind `now`, `unix_time` and `sleep`.
+func (e *Evaluator) SetClock(clock Clock) {
+	e.clock = clock
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// FakeClock stands still until it is advanced. Sleeping on it returns at once
+// and moves it forward by the requested duration.
+type FakeClock struct {
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time { return c.now }
+
+func (c *FakeClock) Sleep(d time.Duration) { c.Advance(d) }
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
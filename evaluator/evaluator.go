@@ -1,8 +1,15 @@
 package evaluator
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"math"
+	"math/rand"
+	"os"
+	"regexp"
+	"time"
 
 	"github.com/arthurlee945/monkey.on/ast"
 	"github.com/arthurlee945/monkey.on/object"
@@ -14,42 +21,132 @@ var (
 	FALSE = &object.Boolean{Value: false}
 )
 
+// Evaluator walks an AST using its own builtin registry and output stream, so
+// hosts can run several interpreters side by side with different capabilities.
+type Evaluator struct {
+	builtins  map[string]*object.Builtin
+	constants map[string]object.Object
+	in        *bufio.Reader
+	out       io.Writer
+	errOut    io.Writer
+	rand      *rand.Rand
+	fs        FileSystem
+	clock     Clock
+	regexps   map[string]*regexp.Regexp // compiled patterns by source
+
+	loader    ModuleLoader
+	modules   map[string]*object.Module // evaluated modules by path
+	importing []string                  // modules being evaluated, outermost first
+
+	depth        int // number of Monkey function calls currently on the stack
+	maxCallDepth int
+}
+
+// DEFAULT_MAX_CALL_DEPTH bounds recursion so runaway scripts fail with an
+// error instead of exhausting the Go stack.
+const DEFAULT_MAX_CALL_DEPTH = 10000
+
+func New(profile Profile) *Evaluator {
+	e := &Evaluator{
+		builtins:     make(map[string]*object.Builtin),
+		constants:    make(map[string]object.Object),
+		regexps:      make(map[string]*regexp.Regexp),
+		modules:      make(map[string]*object.Module),
+		in:           bufio.NewReader(os.Stdin),
+		out:          os.Stdout,
+		errOut:       os.Stderr,
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:        systemClock{},
+		maxCallDepth: DEFAULT_MAX_CALL_DEPTH,
+	}
+	e.Use(profile...)
+	return e
+}
+
+// Eval evaluates node with a fresh STDIO_PROFILE Evaluator, so calls share no
+// state and are safe to make from several goroutines. Use New for other
+// capabilities or to keep caches and settings between calls.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return New(STDIO_PROFILE).Eval(node, env)
+}
+
+// SetMaxCallDepth limits how deeply Monkey functions may nest, including
+// calls made by builtins through Apply. Zero removes the limit.
+func (e *Evaluator) SetMaxCallDepth(depth int) {
+	e.maxCallDepth = depth
+}
+
+// SetOutput redirects where output builtins such as `puts` write to.
+func (e *Evaluator) SetOutput(out io.Writer) {
+	e.out = out
+}
+
+// SetErrorOutput redirects where `eprint` writes to.
+func (e *Evaluator) SetErrorOutput(errOut io.Writer) {
+	e.errOut = errOut
+}
+
+// SetInput sets where `input` and `read_line` read from. A *bufio.Reader is
+// used as is, so a host can keep reading from it between scripts without
+// losing buffered lines.
+func (e *Evaluator) SetInput(in io.Reader) {
+	if reader, ok := in.(*bufio.Reader); ok {
+		e.in = reader
+		return
+	}
+	e.in = bufio.NewReader(in)
+}
+
+// Seed resets the source behind the random builtins so runs are repeatable.
+func (e *Evaluator) Seed(seed int64) {
+	e.rand.Seed(seed)
+}
+
+func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	//STATEMENTS
 	case *ast.Program:
-		return evalProgram(node.Statements, env)
+		return e.evalProgram(node.Statements, env)
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		val := e.Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.Pattern != nil {
+			return e.bindPattern(node.Pattern, val, env, node.IsConst())
+		}
+		return bind(env, node.Name.Value, val, node.IsConst())
+	case *ast.ImportStatement:
+		return e.evalImportStatement(node, env)
+	case *ast.ExportStatement:
+		return e.Eval(node.Statement, env)
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return e.evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
 		return &object.Function{Parameters: params, Body: body, Env: env}
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return e.Eval(node.Expression, env)
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
+		val := e.Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	//EXPRESSIONS
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
+		function := e.Eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args := e.evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return e.applyFunction(function, args)
+	case *ast.PipeExpression:
+		return e.evalPipeExpression(node, env)
 
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
@@ -57,55 +154,65 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.TemplateLiteral:
+		return e.evalTemplateLiteral(node, env)
 	case *ast.ArrayLiteral:
-		elements := evalExpressions(node.Elements, env)
+		elements := e.evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return e.evalHashLiteral(node, env)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		left := e.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		index := Eval(node.Index, env)
+		index := e.Eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.MemberExpression:
+		obj := e.Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return e.evalMemberExpression(obj, node.Property.Value)
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := e.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := e.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := e.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
 	case *ast.BlockStatment:
-		return evalBlockStatement(node.Statements, env)
+		return e.evalBlockStatement(node.Statements, object.NewEnclosedEnvironment(env))
 	case *ast.IFExpression:
-		return evalIfExpression(node, env)
+		return e.evalIfExpression(node, env)
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env)
 	}
 	return nil
 }
 
-func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
+func (e *Evaluator) evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, statments := range stmts {
-		result = Eval(statments, env)
+		result = e.Eval(statments, env)
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
@@ -117,10 +224,15 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	return result
 }
 
-func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.Object {
+// evalBlockStatement runs stmts in env. Every block gets an environment of
+// its own, so a let inside an if or match arm is gone once the block ends and
+// may shadow an outer name, constants included. Closures made in the block
+// keep its environment alive. A function body shares the scope of the
+// parameters instead of adding another one.
+func (e *Evaluator) evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 	for _, statments := range stmts {
-		result = Eval(statments, env)
+		result = e.Eval(statments, env)
 
 		if result != nil {
 			rt := result.Type()
@@ -132,11 +244,11 @@ func evalBlockStatement(stmts []ast.Statement, env *object.Environment) object.O
 	return result
 }
 
-func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+func (e *Evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, exp := range exps {
-		evaluated := Eval(exp, env)
+		evaluated := e.Eval(exp, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
 		}
@@ -146,27 +258,50 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// Apply calls a Monkey function or builtin with already evaluated arguments.
+// It makes the Evaluator an object.Context for builtins.
+func (e *Evaluator) Apply(fn object.Object, args ...object.Object) object.Object {
+	if result := e.applyFunction(fn, args); result != nil {
+		return result
+	}
+	return NULL
+}
+
+func (e *Evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch function := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(function, args)
-		evaluated := Eval(function.Body, extendedEnv)
+		if len(args) < len(function.Parameters) {
+			return newError("wrong number of arguments. got=%d, expected=%d", len(args), len(function.Parameters))
+		}
+		if e.maxCallDepth > 0 && e.depth >= e.maxCallDepth {
+			return newError("maximum call depth exceeded. limit=%d", e.maxCallDepth)
+		}
+		e.depth++
+		defer func() { e.depth-- }()
+
+		extendedEnv, err := e.extendFunctionEnv(function, args)
+		if err != nil {
+			return err
+		}
+		evaluated := e.evalBlockStatement(function.Body.Statements, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
-		return function.Fn(args...)
+		return function.Fn(e, args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func (e *Evaluator) extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, object.Object) {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if err := e.bindPattern(param, args[paramIdx], env, false); err != nil {
+			return nil, err
+		}
 	}
 
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -176,14 +311,17 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
-func evalIdentifier(ident *ast.Identifier, env *object.Environment) object.Object {
+func (e *Evaluator) evalIdentifier(ident *ast.Identifier, env *object.Environment) object.Object {
 
 	if val, ok := env.Get(ident.Value); ok {
 		return val
 	}
-	if builtin, ok := builtins[ident.Value]; ok {
+	if builtin, ok := e.builtins[ident.Value]; ok {
 		return builtin
 	}
+	if constant, ok := e.constants[ident.Value]; ok {
+		return constant
+	}
 	return newError("identifier not found: %s", ident.Value)
 }
 
@@ -218,11 +356,75 @@ func evalIndexExpression(left, index object.Object) object.Object {
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.MODULE_OBJ && index.Type() == object.STRING_OBJ:
+		return evalModuleIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
 }
 
+// evalMemberExpression resolves obj.name: a module export, a key of a hash,
+// or else a method from the method table bound to obj.
+func (e *Evaluator) evalMemberExpression(obj object.Object, name string) object.Object {
+	key := &object.String{Value: name}
+	switch obj := obj.(type) {
+	case *object.Module:
+		return evalModuleIndexExpression(obj, key)
+	case *object.Hash:
+		if pair, ok := obj.Pairs[key.HashKey()]; ok {
+			return pair.Value
+		}
+	}
+
+	if method, ok := e.method(obj, name); ok {
+		return method
+	}
+	if obj.Type() == object.HASH_OBJ {
+		return NULL
+	}
+	return newError("undefined method %s for %s", name, obj.Type())
+}
+
+// evalPipeExpression evaluates x |> f(a) as f(x, a), and x |> f as f(x). Like
+// a call, it evaluates f first and then its arguments, x before a.
+func (e *Evaluator) evalPipeExpression(pe *ast.PipeExpression, env *object.Environment) object.Object {
+	callee := pe.Right
+	call, isCall := pe.Right.(*ast.CallExpression)
+	if isCall {
+		callee = call.Function
+	}
+	function := e.Eval(callee, env)
+	if isError(function) {
+		return function
+	}
+
+	left := e.Eval(pe.Left, env)
+	if isError(left) {
+		return left
+	}
+	args := []object.Object{left}
+	if isCall {
+		rest := e.evalExpressions(call.Arguments, env)
+		if len(rest) == 1 && isError(rest[0]) {
+			return rest[0]
+		}
+		args = append(args, rest...)
+	}
+
+	return e.applyFunction(function, args)
+}
+
+func evalModuleIndexExpression(module, index object.Object) object.Object {
+	moduleObj := module.(*object.Module)
+	name := index.(*object.String).Value
+
+	value, ok := moduleObj.Exports[name]
+	if !ok {
+		return newError("module %q has no export %s", moduleObj.Name, name)
+	}
+	return value
+}
+
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObj := array.(*object.Array)
 	idx := index.(*object.Integer).Value
@@ -236,11 +438,33 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObj.Elements[idx]
 }
 
-func evalHashLiteral(hash *ast.HashLiteral, env *object.Environment) object.Object {
+func (e *Evaluator) evalTemplateLiteral(template *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var out bytes.Buffer
+
+	for i, str := range template.Strings {
+		out.WriteString(str)
+		if i >= len(template.Expressions) {
+			break
+		}
+
+		value := e.Eval(template.Expressions[i], env)
+		if isError(value) {
+			return value
+		}
+		if value == nil {
+			value = NULL
+		}
+		out.WriteString(value.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func (e *Evaluator) evalHashLiteral(hash *ast.HashLiteral, env *object.Environment) object.Object {
 	pairs := make(map[object.HashKey]object.HashPair)
 
 	for keyNode, valueNode := range hash.Pairs {
-		key := Eval(keyNode, env)
+		key := e.Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
@@ -250,7 +474,7 @@ func evalHashLiteral(hash *ast.HashLiteral, env *object.Environment) object.Obje
 			return newError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := e.Eval(valueNode, env)
 		if isError(value) {
 			return value
 		}
@@ -284,6 +508,8 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case isTimeValue(left) || isTimeValue(right):
+		return evalTimeInfixExpression(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
@@ -295,19 +521,22 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
-func evalIfExpression(ie *ast.IFExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
-	if isError(condition) {
-		return condition
+func (e *Evaluator) evalIfExpression(ie *ast.IFExpression, env *object.Environment) object.Object {
+	for _, arm := range ie.Arms {
+		condition := e.Eval(arm.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return e.Eval(arm.Consequence, env)
+		}
 	}
 
-	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
-	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
-	} else {
-		return NULL
+	if ie.Alternative != nil {
+		return e.Eval(ie.Alternative, env)
 	}
+	return NULL
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
@@ -322,9 +551,19 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		// Integer division truncates toward zero, so -7 / 2 is -3 and -7 % 2
+		// is -1. The `div` and `mod` builtins round toward negative infinity.
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		return integerPower(leftVal, rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -338,6 +577,44 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// integerPower keeps integer results for non-negative exponents and falls
+// back to a FLOAT for negative ones, where the result is fractional. Results
+// that do not fit an INTEGER are an error rather than wrapping around.
+func integerPower(base, exp int64) object.Object {
+	if exp < 0 {
+		return &object.Float{Value: math.Pow(float64(base), float64(exp))}
+	}
+
+	result, square, n := int64(1), base, exp
+	var ok bool
+	for n > 0 {
+		if n&1 == 1 {
+			if result, ok = multiplyIntegers(result, square); !ok {
+				return newError("integer overflow: %d ** %d", base, exp)
+			}
+		}
+		n >>= 1
+		if n > 0 {
+			if square, ok = multiplyIntegers(square, square); !ok {
+				return newError("integer overflow: %d ** %d", base, exp)
+			}
+		}
+	}
+	return &object.Integer{Value: result}
+}
+
+// multiplyIntegers returns a * b and whether it fits in an int64.
+func multiplyIntegers(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return 0, false
+	}
+	return product, true
+}
+
 func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
 	var leftVal float64
 	var rightVal float64
@@ -363,6 +640,8 @@ func evalFloatInfixExpression(operator string, left, right object.Object) object
 		return &object.Float{Value: leftVal / rightVal}
 	case "%":
 		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -376,12 +655,113 @@ func evalFloatInfixExpression(operator string, left, right object.Object) object
 	}
 }
 
+func isTimeValue(obj object.Object) bool {
+	return obj.Type() == object.TIME_OBJ || obj.Type() == object.DURATION_OBJ
+}
+
+// evalTimeInfixExpression handles TIME and DURATION operands: TIME - TIME is a
+// DURATION, TIME +/- DURATION is a TIME, and DURATIONs add up, scale by
+// numbers and divide into each other as a FLOAT ratio.
+func evalTimeInfixExpression(operator string, left, right object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Time:
+		switch right := right.(type) {
+		case *object.Time:
+			switch operator {
+			case "-":
+				return &object.Duration{Value: left.Value.Sub(right.Value)}
+			case "<":
+				return nativeBoolToBooleanObject(left.Value.Before(right.Value))
+			case ">":
+				return nativeBoolToBooleanObject(left.Value.After(right.Value))
+			case "==":
+				return nativeBoolToBooleanObject(left.Value.Equal(right.Value))
+			case "!=":
+				return nativeBoolToBooleanObject(!left.Value.Equal(right.Value))
+			}
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.Time{Value: left.Value.Add(right.Value)}
+			case "-":
+				return &object.Time{Value: left.Value.Add(-right.Value)}
+			}
+		}
+	case *object.Duration:
+		switch right := right.(type) {
+		case *object.Time:
+			if operator == "+" {
+				return &object.Time{Value: right.Value.Add(left.Value)}
+			}
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return &object.Duration{Value: left.Value + right.Value}
+			case "-":
+				return &object.Duration{Value: left.Value - right.Value}
+			case "/":
+				if right.Value == 0 {
+					return newError("division by zero")
+				}
+				return &object.Float{Value: float64(left.Value) / float64(right.Value)}
+			case "<":
+				return nativeBoolToBooleanObject(left.Value < right.Value)
+			case ">":
+				return nativeBoolToBooleanObject(left.Value > right.Value)
+			case "==":
+				return nativeBoolToBooleanObject(left.Value == right.Value)
+			case "!=":
+				return nativeBoolToBooleanObject(left.Value != right.Value)
+			}
+		case *object.Integer, *object.Float:
+			factor := toFloat(right)
+			switch operator {
+			case "*":
+				return scaledDuration(float64(left.Value)*factor, operator, left, right)
+			case "/":
+				if factor == 0 {
+					return newError("division by zero")
+				}
+				return scaledDuration(float64(left.Value)/factor, operator, left, right)
+			}
+		}
+	case *object.Integer, *object.Float:
+		if d, ok := right.(*object.Duration); ok && operator == "*" {
+			return scaledDuration(toFloat(left)*float64(d.Value), operator, left, right)
+		}
+	}
+
+	switch operator {
+	case "==":
+		return FALSE
+	case "!=":
+		return TRUE
+	}
+	if left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// scaledDuration wraps the result of multiplying or dividing a duration,
+// failing when it falls outside the DURATION range.
+func scaledDuration(value float64, operator string, left, right object.Object) object.Object {
+	if math.IsNaN(value) || value >= math.MaxInt64 || value < math.MinInt64 {
+		return newError("duration overflow: %s %s %s", left.Inspect(), operator, right.Inspect())
+	}
+	return &object.Duration{Value: time.Duration(value)}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -397,6 +777,8 @@ func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 		return &object.Integer{Value: -right.Value}
 	case *object.Float:
 		return &object.Float{Value: -right.Value}
+	case *object.Duration:
+		return &object.Duration{Value: -right.Value}
 	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
@@ -1,8 +1,11 @@
 package evaluator
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/arthurlee945/monkey.on/ast"
 	"github.com/arthurlee945/monkey.on/lexer"
 	"github.com/arthurlee945/monkey.on/object"
 	"github.com/arthurlee945/monkey.on/parser"
@@ -28,6 +31,13 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"(15 * 3) % 4 + 10", 11},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"-7 / 2", -3},
+		{"-7 % 2", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
 	}
 	for _, tt := range tests {
 		obj := testEval(tt.input)
@@ -49,6 +59,9 @@ func TestEvalFloatExpression(t *testing.T) {
 		{"12.24 * (2.6 + 2.4)", 61.2},
 		{"(5 * 3) % 3.5 + 10", 11},
 		{"(5 + 10.4 * 2 + 15.0 / 4.0) * 2 + -10", 49.1},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8},
+		{"9 ** 0.5", 3},
 	}
 	for _, tt := range tests {
 		obj := testEval(tt.input)
@@ -84,6 +97,36 @@ func TestEvalStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestEvalTemplateLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`plain`", "plain"},
+		{"let name = \"Monkey\"; `Hello ${name}!`", "Hello Monkey!"},
+		{"`n=${1 + 1}, f=${2.5}, b=${true}, nil=${if (false) { 1 }}`", "n=2, f=2.500000, b=true, nil=null"},
+		{"`${[1, \"a\"]} ${len(\"abc\")}`", "[1, a] 3"},
+		{"let f = fn(x){ `<${x}>` }; f(f(1))", "<<1>>"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+
+	evaluated := testEval("`${missing}`")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Message != "identifier not found: missing" {
+		t.Errorf("expected identifier error. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
 func TestEvalArrayLiteral(t *testing.T) {
 	input := "[1, 2, 3 * 2, 4 + 5]"
 
@@ -203,6 +246,10 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if(8==8){ 20 }else{88}", 20},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"let x = 5; if (x < 3) { 1 } else if (x < 6) { 2 } else { 3 }", 2},
+		{"let x = 9; if (x < 3) { 1 } else if (x < 6) { 2 } else { 3 }", 3},
+		{"let x = 1; if (x < 3) { 1 } else if (x < 6) { 2 } else { 3 }", 1},
+		{"if (false) { 1 } else if (false) { 2 }", nil},
 	}
 
 	for _, tt := range tests {
@@ -258,6 +305,152 @@ func TestLetStatement(t *testing.T) {
 	}
 }
 
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [first, ...rest] = [1, 2, 3]; rest", []interface{}{2, 3}},
+		{"let [x, ...rest] = [1]; rest", []interface{}{}},
+		{"let [[a, b], [c]] = [[1, 2], [3]]; a + b + c", 6},
+		{`let {name, age: years} = {"name": "momo", "age": 3}; name + str(years)`, "momo3"},
+		{`let {pos: [x, y]} = {"pos": [3, 4]}; x * y`, 12},
+		{`let {a, ...others} = {"a": 1, "b": 2, "c": 3}; keys(others)`, []interface{}{"b", "c"}},
+		{"let swap = fn([a, b]) { [b, a] }; swap([1, 2])", []interface{}{2, 1}},
+		{`let area = fn({w, h}) { w * h }; area({"w": 2, "h": 5, "d": 9})`, 10},
+		{`[[1, 2], [3, 4]] |> map(fn([a, b]) { a + b })`, []interface{}{3, 7}},
+		{"let [a, b] = [1, 2, 3];", errorMessage("cannot destructure array of 3 elements into [a, b]")},
+		{"let [a, b, ...c] = [1];", errorMessage("cannot destructure array of 1 elements into [a, b, ...c]")},
+		{"let [a] = 1;", errorMessage("cannot destructure INTEGER as an array")},
+		{`let {a} = [1];`, errorMessage("cannot destructure ARRAY as a hash")},
+		{`let {a, b} = {"a": 1};`, errorMessage(`cannot destructure hash without key "b"`)},
+		{`let f = fn([a]) { a }; f({"a": 1})`, errorMessage("cannot destructure HASH as an array")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashPatternKeyErrors(t *testing.T) {
+	// the parser only accepts literal keys, so build the bad keys by hand
+	tests := []struct {
+		key      ast.Expression
+		expected string
+	}{
+		{
+			&ast.IndexExpression{Left: &ast.StringLiteral{Value: "a"}, Index: &ast.IntegerLiteral{Value: 0}},
+			"index operator not supported: STRING",
+		},
+		{&ast.ArrayLiteral{}, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(`let {"a": b} = {"a": 1};`)).ParseProgram()
+		pattern := program.Statements[0].(*ast.LetStatement).Pattern.(*ast.HashPattern)
+		pattern.Pairs[0].Key = tt.key
+
+		testExpected(t, program.String(), New(PURE_PROFILE).Eval(program, object.NewEnvironment()), errorMessage(tt.expected))
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`match (1) { 1 => "one", _ => "many" }`, "one"},
+		{`match (5) { 1 => "one", _ => "many" }`, "many"},
+		{`match (2.0) { 2 => "int two", _ => "other" }`, "int two"},
+		{`match (-3) { -3 => true, _ => false }`, true},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{`match (false) { true => 1, false => 0 }`, 0},
+		{`match ([1, 2]) { [x] => x, [x, y] => x + y }`, 3},
+		{`match ([1, 2, 3]) { [1, ...rest] => rest, _ => [] }`, []interface{}{2, 3}},
+		{`match ([4, 1]) { [a, b] if a < b => "asc", [a, b] => "desc" }`, "desc"},
+		{`match ({"type": "add", "l": 2, "r": 3}) { {"type": "neg", l} => -l, {"type": "add", l, r} => l + r }`, 5},
+		{`match ({"pos": [0, 7]}) { {pos: [0, y]} => y, _ => -1 }`, 7},
+		{`match ({1: "one"}) { {1: name} => name }`, "one"},
+		{`match (10) { n if n > 5 => n * 2, n => n }`, 20},
+		{`match (3) { _ => { let doubled = 3 * 2; doubled } }`, 6},
+		{`let f = fn(x) { match (x) { 0 => { return "zero" }, _ => 1 }; "after" }; f(0)`, "zero"},
+		{`let x = 1; match (2) { x => x }; x`, 1},
+		{`let area = fn(shape) { match (shape) { {"kind": "square", side} => side * side, {"kind": "rect", w, h} => w * h } }; area({"kind": "rect", "w": 2, "h": 3})`, 6},
+		{`match (7) { 1 => 1 }`, errorMessage("no match for 7")},
+		{`match ([1]) { }`, errorMessage("no match for [1]")},
+		{`match (1) { n if missing => n }`, errorMessage("identifier not found: missing")},
+		{`let [_, b] = [1, 2]; b`, 2},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestConstAndFreeze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const x = 5; x * 2", 10},
+		{"const x = 5; let x = 6;", errorMessage("cannot redeclare constant x")},
+		{"const x = 5; const x = 6;", errorMessage("cannot redeclare constant x")},
+		{"const [a, ...rest] = [1, 2]; let rest = 3;", errorMessage("cannot redeclare constant rest")},
+		{"const {a} = {\"a\": 1}; let f = fn() { let a = 2; a }; f() + a", 3},
+		{"const x = 1; let f = fn(x) { x * 10 }; f(2) + x", 21},
+		{"let x = 1; const x = 2; x", 2},
+		{"const x = 1; match (5) { x => x }", 5},
+		{"let xs = freeze([1, [2, 3]]); is_frozen(xs[1])", true},
+		{"let xs = freeze([1, 2]); push(xs, 3)", []interface{}{1, 2, 3}},
+		{"is_frozen(push(freeze([1]), 2))", false},
+		{`let h = freeze({"a": {"b": 1}}); is_frozen(h["a"])`, true},
+		{`let h = freeze({"a": 1}); delete(h, "a")`, errorMessage("cannot delete from a frozen HASH")},
+		{`let h = {"a": 1}; delete(h, "a"); len(h)`, 0},
+		{`is_frozen({"a": 1})`, false},
+		{"is_frozen(1)", true},
+		{"freeze(1)", 1},
+		{"[1].freeze().is_frozen()", true},
+		{"freeze()", errorMessage("wrong number of arguments. got=0, expected=1")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	e := New(PURE_PROFILE)
+	e.SetModuleLoader(NewModuleLoader(NewMemFS(map[string]string{
+		"limits.mk": "export const max = 3;",
+	})))
+	input := "import { max } from \"limits\"; max"
+	testExpected(t, input, testEvalWith(e, input), 3)
+}
+
+func TestBlockScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { let inner = 1; }; inner", errorMessage("identifier not found: inner")},
+		{"let x = 1; if (true) { let x = 2; x }", 2},
+		{"let x = 1; if (true) { let x = 2; }; x", 1},
+		{"let x = 1; if (false) { 0 } else { let x = 3; }; x", 1},
+		{"let x = 1; if (true) { let y = x + 1; y }", 2},
+		{"let f = fn() { if (true) { let hidden = 1; }; hidden }; f()", errorMessage("identifier not found: hidden")},
+		{"let f = fn(x) { let x = x + 1; x }; f(1)", 2},
+		{"let make = fn() { if (true) { let n = 10; fn() { n } } }; make()()", 10},
+		{"let n = 1; let g = if (true) { let n = 2; fn() { n } }; g() + n", 3},
+		{"const c = 1; if (true) { const c = 2; c }", 2},
+		{"const c = 1; if (true) { let c = 2; }; c", 1},
+		{"match (1) { _ => { let m = 1; } }; m", errorMessage("identifier not found: m")},
+		{"let f = fn() { if (true) { return 5; }; 0 }; f()", 5},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x){x+2;};"
 
@@ -314,6 +507,24 @@ func TestClosures(t *testing.T) {
 	testIntegerObject(t, testEval(input), 18)
 }
 
+func TestMaxCallDepth(t *testing.T) {
+	e := New(PURE_PROFILE)
+	e.SetMaxCallDepth(50)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let count = fn(n){ if (n == 0) { 0 } else { 1 + count(n - 1) } }; count(40)", 40},
+		{"let count = fn(n){ if (n == 0) { 0 } else { 1 + count(n - 1) } }; count(60)", errorMessage("maximum call depth exceeded. limit=50")},
+		{"let loop = fn(x){ map([x], loop) }; loop(1)", errorMessage("maximum call depth exceeded. limit=50")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+}
+
 func TestBuiltinFunction(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -348,6 +559,266 @@ func TestBuiltinFunction(t *testing.T) {
 	}
 }
 
+func TestStdioStreams(t *testing.T) {
+	var out, errOut bytes.Buffer
+	e := New(STDIO_PROFILE)
+	e.SetInput(strings.NewReader("Jo\r\nsecond\nlast"))
+	e.SetOutput(&out)
+	e.SetErrorOutput(&errOut)
+
+	input := `
+	let name = input("name? ");
+	print("hi ", name, "!");
+	eprint("oops", 1);
+	[read_line(), read_line(), read_line()]
+	`
+	evaluated := testEvalWith(e, input)
+	testExpected(t, input, evaluated, []interface{}{"second", "last", nil})
+
+	if out.String() != "name? hi Jo!" {
+		t.Errorf("wrong output. expected=%q, got=%q", "name? hi Jo!", out.String())
+	}
+	if errOut.String() != "oops1" {
+		t.Errorf("wrong error output. expected=%q, got=%q", "oops1", errOut.String())
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`input(1)`, "argument 1 to `input` must be STRING, got INTEGER"},
+		{`input("a", "b")`, "wrong number of arguments. got=2, expected=0 or 1"},
+		{`read_line(1)`, "wrong number of arguments. got=1, expected=0"},
+	}
+	for _, tt := range errorTests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), errorMessage(tt.expected))
+	}
+}
+
+func TestImportModules(t *testing.T) {
+	fsys := NewMemFS(map[string]string{
+		"lib/math.mk": `
+			let square = fn(x) { x * x };
+			export let cube = fn(x) { x * square(x) };
+			export let answer = 42;
+			puts("loading math");
+		`,
+		"lib/stats.mk": `
+			import { cube } from "./math";
+			export let cubes = fn(xs) { map(xs, cube) };
+		`,
+		"app/util.mk": `export let name = "util";`,
+		"broken.mk":   `let x 1;`,
+		"failing.mk":  `export let x = 1 + true;`,
+		"cycle/a.mk":  `import "./b" as b; export let a = 1;`,
+		"cycle/b.mk":  `import "./a.mk" as a; export let b = 2;`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "lib/math.mk" as m; m["cube"](3)`, 27},
+		{`import { cube, answer } from "lib/math"; cube(2) + answer`, 50},
+		{`import { cubes } from "lib/stats"; cubes([1, 2])`, []interface{}{1, 8}},
+		{`import "util" as u; u["name"]`, "util"},
+		{`import "lib/math" as m; m["square"]`, errorMessage(`module "lib/math.mk" has no export square`)},
+		{`import { square } from "lib/math"`, errorMessage(`module "lib/math.mk" has no export square`)},
+		{`import "lib/math" as m; type(m)`, "MODULE"},
+		{`import "missing" as m`, errorMessage(`cannot import "missing": module not found`)},
+		{`import "../secret" as m`, errorMessage(`cannot import "../secret": module path ../secret is outside the loader root`)},
+		{`import "broken" as b`, errorMessage(`cannot parse module "broken.mk": expected next token to be =, got INT instead`)},
+		{`import "failing" as f`, errorMessage("type mismatch: INTEGER + BOOLEAN")},
+		{`import "cycle/a" as a`, errorMessage("import cycle: cycle/a.mk -> cycle/b.mk -> cycle/a.mk")},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := New(PURE_PROFILE)
+		e.Use(STDIO_CAP)
+		e.SetOutput(&out)
+		e.SetModuleLoader(NewModuleLoader(fsys, ".", "app"))
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+
+	var out bytes.Buffer
+	e := New(STDIO_PROFILE)
+	e.SetOutput(&out)
+	e.SetModuleLoader(NewModuleLoader(fsys))
+	testEvalWith(e, `import "lib/math" as a; import "lib/math.mk" as b; import { cubes } from "lib/stats";`)
+	if out.String() != "loading math\n" {
+		t.Errorf("module should be evaluated once. got output=%q", out.String())
+	}
+
+	evaluated := testEvalWith(New(PURE_PROFILE), `import "lib/math" as m`)
+	testExpected(t, "import without loader", evaluated, errorMessage(`cannot import "lib/math": no module loader configured`))
+}
+
+func TestMemberExpressions(t *testing.T) {
+	e := New(PURE_PROFILE)
+	e.SetModuleLoader(NewModuleLoader(NewMemFS(map[string]string{
+		"geo.mk":   `export let origin = {"x": 0, "y": 0}; export let dist = fn(p) { sqrt(p.x ** 2 + p.y ** 2) };`,
+		"pairs.mk": `export let [lo, hi] = [1, 2];`,
+	})))
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let p = {"x": 3, "y": 4}; p.x + p.y`, 7},
+		{`{"inner": {"name": "momo"}}.inner.name`, "momo"},
+		{`{"a": 1}.missing`, nil},
+		{`{"double": fn(x) { x * 2 }}.double(21)`, 42},
+		{`let ops = {"add": fn(a, b) { a + b }}; ops.add(1, 2) * 3`, 9},
+		{`[{"v": 1}, {"v": 2}][1].v`, 2},
+		{`import "geo" as geo; geo.dist({"x": 3, "y": 4})`, 5.0},
+		{`import "geo" as geo; geo.origin.x`, 0},
+		{`import "pairs" as pairs; pairs.lo + pairs.hi`, 3},
+		{`import "geo" as geo; geo.nope`, errorMessage(`module "geo.mk" has no export nope`)},
+		{`let n = 1; n.x`, errorMessage("undefined method x for INTEGER")},
+		{`{1: "one"}.one`, nil},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(e, tt.input), tt.expected)
+	}
+}
+
+func TestMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`[1, 2].push(3)`, []interface{}{1, 2, 3}},
+		{`let arr = [[1, 2], [3]]; arr.rest().push(arr.first().len())`, []interface{}{[]interface{}{3}, 2}},
+		{`[3, 1, 2].sort().map(fn(x) { x * 10 }).join(",")`, "10,20,30"},
+		{`[1, 2, 3, 4].filter(fn(x) { x % 2 == 0 }).sum()`, 6},
+		{`" Monkey ".trim().upper()`, "MONKEY"},
+		{`"a,b".split(",").len()`, 2},
+		{`"{} {}".format("a", 1)`, "a 1"},
+		{`"42".to_int() + 1`, 43},
+		{`{"b": 2, "a": 1}.keys()`, []interface{}{"a", "b"}},
+		{`{"a": 1}.has("a")`, true},
+		{`{"keys": "mine"}.keys`, "mine"},
+		{`(-3).abs()`, 3},
+		{`2.5.floor()`, 2},
+		{`7.to_string() + "!"`, "7!"},
+		{`[1].to_string()`, "[1]"},
+		{`true.type()`, "BOOLEAN"},
+		{`{"a": [1]}.to_json()`, `{"a":[1]}`},
+		{`let up = "abc".upper; up()`, "ABC"},
+		{`[1].nope()`, errorMessage("undefined method nope for ARRAY")},
+		{`"abc".push(1)`, errorMessage("undefined method push for STRING")},
+		{`[1].push()`, errorMessage("wrong number of arguments. got=1, expected=2")},
+		{`[1].shuffle()`, errorMessage("undefined method shuffle for ARRAY")},
+		{`methods(true)`, []interface{}{"to_json", "to_string", "type"}},
+		{`contains(methods(""), "upper")`, true},
+		{`1.methods`, errorMessage("undefined method methods for INTEGER")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(New(PURE_PROFILE), tt.input), tt.expected)
+	}
+}
+
+func TestPipeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`[1, 2, 3] |> len`, 3},
+		{`[1, 2, 3] |> map(fn(x) { x * 2 }) |> sum()`, 12},
+		{`let add = fn(a, b) { a + b }; 1 + 1 |> add(40)`, 42},
+		{`[3, 1, 2] |> sort() |> join("-")`, "1-2-3"},
+		{`" monkey " |> trim |> upper`, "MONKEY"},
+		{`let lib = {"twice": fn(x) { x * 2 }}; 4 |> lib.twice`, 8},
+		{`5 |> fn(x) { x + 1 }`, 6},
+		{`[1, 2] |> len == 2`, true},
+		{`[1, 2] |> len == [3, 4] |> len`, true},
+		{`[1] |> nope()`, errorMessage("identifier not found: nope")},
+		{`[1] |> push(missing)`, errorMessage("identifier not found: missing")},
+		{`lhs |> nope(rhs)`, errorMessage("identifier not found: nope")},
+		{`lhs |> len(rhs)`, errorMessage("identifier not found: lhs")},
+		{`lhs |> nope`, errorMessage("identifier not found: nope")},
+		{`1 |> 2`, errorMessage("not a function: INTEGER")},
+		{`"x" |> len(1)`, errorMessage("wrong number of arguments. got=2, expected=1")},
+	}
+
+	for _, tt := range tests {
+		testExpected(t, tt.input, testEvalWith(New(PURE_PROFILE), tt.input), tt.expected)
+	}
+}
+
+func TestBuiltinProfiles(t *testing.T) {
+	tests := []struct {
+		profile  Profile
+		input    string
+		expected string
+	}{
+		{PURE_PROFILE, `puts("monkey")`, "identifier not found: puts"},
+		{STDIO_PROFILE, `puts("monkey", 8)`, "monkey\n8\n"},
+		{FULL_PROFILE, `puts(len("paw"))`, "3\n"},
+		{STDIO_PROFILE, `random()`, "identifier not found: random"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := New(tt.profile)
+		e.SetOutput(&out)
+
+		evaluated := testEvalWith(e, tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if out.String() != tt.expected {
+			t.Errorf("wrong output. expected=%q, got=%q", tt.expected, out.String())
+		}
+	}
+}
+
+func TestPackageEval(t *testing.T) {
+	for _, input := range []string{`random()`, `read_file("x")`, `now()`} {
+		name := input[:strings.Index(input, "(")]
+		testExpected(t, input, testEval(input), errorMessage("identifier not found: "+name))
+	}
+
+	// every call gets its own evaluator, so concurrent calls share no caches
+	done := make(chan object.Object)
+	for i := 0; i < 8; i++ {
+		go func() {
+			done <- testEval(`let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(50); regex_match("a+", "aa")`)
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		testBooleanObject(t, <-done, true)
+	}
+}
+
+func TestBuiltinRegistry(t *testing.T) {
+	e := New(PURE_PROFILE)
+	e.Register("double", func(ctx object.Context, args ...object.Object) object.Object {
+		return &object.Integer{Value: args[0].(*object.Integer).Value * 2}
+	})
+	testIntegerObject(t, testEvalWith(e, "double(21)"), 42)
+
+	e.Unregister("len")
+	evaluated := testEvalWith(e, `len("monkey")`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "identifier not found: len" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+
+	if _, ok := New(PURE_PROFILE).Builtin("len"); !ok {
+		t.Errorf("unregistering on one evaluator affected another")
+	}
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -356,6 +827,8 @@ func TestErrorHandling(t *testing.T) {
 		{"5 + true", "type mismatch: INTEGER + BOOLEAN"},
 		{"true + 5; 5;", "type mismatch: BOOLEAN + INTEGER"},
 		{"-true", "unknown operator: -BOOLEAN"},
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
 		{"true + false", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"if( 10 > 1) {true + false}", "unknown operator: BOOLEAN + BOOLEAN"},
@@ -457,6 +930,14 @@ func testEval(input string) object.Object {
 
 	return Eval(program, env)
 }
+func testEvalWith(e *Evaluator, input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return e.Eval(program, env)
+}
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
 	if !ok {
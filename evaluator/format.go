@@ -0,0 +1,172 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// maxFormatWidth bounds the width and precision of a format spec, well below
+// the point where Go's fmt gives up on them.
+const maxFormatWidth = 1000
+
+// formatString implements the `format` builtin. A placeholder is {} for the
+// next argument or {N} for argument N, optionally followed by :spec where
+// spec is [<|>][width][.precision][type] and type is one of d, x, o, b
+// (integers), f, e, g (numbers) or s. Width and precision are at most
+// maxFormatWidth. {{ and }} produce literal braces.
+//
+//	format("{} is {:.2f}", "pi", 3.14159) // "pi is 3.14"
+func formatString(template string, args []object.Object) (string, object.Object) {
+	var out strings.Builder
+	next := 0
+
+	for i := 0; i < len(template); i++ {
+		ch := template[i]
+		switch {
+		case ch == '{' && i+1 < len(template) && template[i+1] == '{':
+			out.WriteByte('{')
+			i++
+		case ch == '}' && i+1 < len(template) && template[i+1] == '}':
+			out.WriteByte('}')
+			i++
+		case ch == '}':
+			return "", newError("unmatched } in format string")
+		case ch == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", newError("unclosed { in format string")
+			}
+			field := template[i+1 : i+end]
+			i += end
+
+			index, spec, _ := strings.Cut(field, ":")
+			argIdx := next
+			if index == "" {
+				next++
+			} else {
+				n, err := strconv.Atoi(index)
+				if err != nil || n < 0 {
+					return "", newError("invalid placeholder {%s}", field)
+				}
+				argIdx = n
+			}
+			if argIdx >= len(args) {
+				return "", newError("format needs argument %d, got %d arguments", argIdx+1, len(args))
+			}
+
+			formatted, err := formatValue(args[argIdx], spec)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(formatted)
+		default:
+			out.WriteByte(ch)
+		}
+	}
+
+	return out.String(), nil
+}
+
+func formatValue(obj object.Object, spec string) (string, object.Object) {
+	rest := spec
+	flags := ""
+	if strings.HasPrefix(rest, "<") {
+		flags, rest = "-", rest[1:]
+	} else if strings.HasPrefix(rest, ">") {
+		rest = rest[1:]
+	}
+
+	width := leadingDigits(rest)
+	rest = rest[len(width):]
+
+	precision := ""
+	hasPrecision := strings.HasPrefix(rest, ".")
+	if hasPrecision {
+		precision = leadingDigits(rest[1:])
+		if precision == "" {
+			return "", newError("invalid format spec %q", spec)
+		}
+		rest = rest[1+len(precision):]
+	}
+	if err := checkFormatWidth("width", width, spec); err != nil {
+		return "", err
+	}
+	if err := checkFormatWidth("precision", precision, spec); err != nil {
+		return "", err
+	}
+
+	verb := rest
+	if verb == "" && hasPrecision && isNumber(obj) {
+		verb = "f"
+	}
+	if len(verb) > 1 {
+		return "", newError("invalid format spec %q", spec)
+	}
+
+	switch verb {
+	case "d", "x", "o", "b":
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return "", newError("format spec {:%s} needs INTEGER, got %s", spec, obj.Type())
+		}
+		return fmt.Sprintf("%"+flags+width+verb, integer.Value), nil
+	case "f", "e", "g":
+		if !isNumber(obj) {
+			return "", newError("format spec {:%s} needs a number, got %s", spec, obj.Type())
+		}
+		if hasPrecision {
+			return fmt.Sprintf("%"+flags+width+"."+precision+verb, toFloat(obj)), nil
+		}
+		return fmt.Sprintf("%"+flags+width+verb, toFloat(obj)), nil
+	case "", "s":
+		if hasPrecision {
+			return fmt.Sprintf("%"+flags+width+"."+precision+"s", stringify(obj)), nil
+		}
+		return fmt.Sprintf("%"+flags+width+"s", stringify(obj)), nil
+	default:
+		return "", newError("unknown format type %q in {:%s}", verb, spec)
+	}
+}
+
+// checkFormatWidth rejects a width or precision above maxFormatWidth. An
+// empty digits string means the spec left it out.
+func checkFormatWidth(name, digits, spec string) object.Object {
+	if digits == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(digits); err != nil || n > maxFormatWidth {
+		return newError("format %s in {:%s} must not exceed %d", name, spec, maxFormatWidth)
+	}
+	return nil
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && '0' <= s[end] && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// nativeValues converts arguments for Go's fmt verbs, as used by `sprintf`.
+func nativeValues(args []object.Object) []interface{} {
+	values := make([]interface{}, len(args))
+	for idx, arg := range args {
+		switch arg := arg.(type) {
+		case *object.Integer:
+			values[idx] = arg.Value
+		case *object.Float:
+			values[idx] = arg.Value
+		case *object.String:
+			values[idx] = arg.Value
+		case *object.Boolean:
+			values[idx] = arg.Value
+		default:
+			values[idx] = arg.Inspect()
+		}
+	}
+	return values
+}
@@ -0,0 +1,286 @@
+package evaluator
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileSystem is what the FS builtins read and write through. Names are
+// slash-separated and relative to the file system's root, as in io/fs; names
+// such as "../x" are rejected, but whether links can lead out of the root is
+// up to the implementation.
+type FileSystem interface {
+	fs.FS
+	WriteFile(name string, data []byte) error
+	AppendFile(name string, data []byte) error
+	Remove(name string) error
+}
+
+// errOutsideRoot is reported for names that resolve, through a symlink, to
+// somewhere outside a DirFS root.
+var errOutsideRoot = errors.New("path escapes from the file system root")
+
+// SetFileSystem gives the FS builtins somewhere to work. Without one they fail
+// with an error, even when the FS capability is granted.
+func (e *Evaluator) SetFileSystem(fsys FileSystem) {
+	e.fs = fsys
+}
+
+type dirFS struct {
+	root string
+}
+
+// DirFS exposes the directory tree rooted at root on the host. Symlinks are
+// followed only while they resolve inside root; the check happens before each
+// operation, so it does not guard against the tree changing underneath.
+func DirFS(root string) FileSystem {
+	return &dirFS{root: root}
+}
+
+// path resolves name to a host path, refusing invalid names and names whose
+// deepest existing ancestor is a symlink out of the root.
+func (d *dirFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	p := filepath.Join(d.root, filepath.FromSlash(name))
+
+	root, err := filepath.EvalSymlinks(d.root)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	existing := p
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: name, Err: errOutsideRoot}
+	}
+
+	return p, nil
+}
+
+func (d *dirFS) Open(name string) (fs.File, error) {
+	p, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (d *dirFS) WriteFile(name string, data []byte) error {
+	p, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func (d *dirFS) AppendFile(name string, data []byte) error {
+	p, err := d.path("append", name)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (d *dirFS) Remove(name string) error {
+	if name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// MemFS is an in-memory FileSystem, handy for tests and for hosts that want
+// scripts to exchange files without touching disk. Directories exist
+// implicitly whenever a file lives below them.
+type MemFS struct {
+	files map[string][]byte
+}
+
+// NewMemFS creates a MemFS holding the given files, keyed by path.
+func NewMemFS(files map[string]string) *MemFS {
+	m := &MemFS{files: make(map[string][]byte, len(files))}
+	for name, content := range files {
+		m.files[name] = []byte(content)
+	}
+	return m
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m.files[name]; ok {
+		info := &memFileInfo{name: path.Base(name), size: int64(len(data))}
+		return &memFile{info: info, Reader: bytes.NewReader(data)}, nil
+	}
+	entries, ok := m.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memDir{info: &memFileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// readDir lists the files and implicit directories directly below dir, and
+// reports whether dir exists at all.
+func (m *MemFS) readDir(dir string) ([]fs.DirEntry, bool) {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	found := dir == "."
+	seen := make(map[string]bool)
+	entries := []fs.DirEntry{}
+	for name, data := range m.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		found = true
+		child, _, isDir := strings.Cut(name[len(prefix):], "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := &memFileInfo{name: child, size: int64(len(data)), dir: isDir}
+		if isDir {
+			info.size = 0
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, found
+}
+
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	if err := m.checkWritable("write", name); err != nil {
+		return err
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemFS) AppendFile(name string, data []byte) error {
+	if err := m.checkWritable("append", name); err != nil {
+		return err
+	}
+	m.files[name] = append(m.files[name], data...)
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// checkWritable rejects invalid names and names that are already in use as a
+// directory.
+func (m *MemFS) checkWritable(op, name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if _, ok := m.files[name]; !ok {
+		if _, isDir := m.readDir(name); isDir {
+			return &fs.PathError{Op: op, Path: name, Err: fs.ErrExist}
+		}
+	}
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+		}
+	}
+	return nil
+}
+
+type memFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i *memFileInfo) Name() string       { return i.name }
+func (i *memFileInfo) Size() int64        { return i.size }
+func (i *memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *memFileInfo) IsDir() bool        { return i.dir }
+func (i *memFileInfo) Sys() any           { return nil }
+func (i *memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+type memFile struct {
+	*bytes.Reader
+	info *memFileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	info    *memFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
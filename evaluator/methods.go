@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// methodTable maps, per receiver type, a method name to the builtin it calls
+// with the receiver as first argument: arr.push(x) is push(arr, x). Methods
+// only exist while the builtin behind them is registered, so a profile
+// without RANDOM has no arr.shuffle either.
+var methodTable = map[object.ObjectType]map[string]string{
+	object.ARRAY_OBJ: sameNames(
+		"len", "first", "last", "rest", "push", "map", "filter", "each", "reduce",
+		"sort", "sort_by", "find", "any", "all", "reverse", "concat", "slice",
+		"index_of", "contains", "unique", "flatten", "zip", "join", "sum", "min",
+		"max", "choice", "shuffle", "freeze", "is_frozen",
+	),
+	object.HASH_OBJ: sameNames(
+		"len", "keys", "values", "entries", "has", "get", "delete", "merge",
+		"map", "filter", "each", "freeze", "is_frozen",
+	),
+	object.STRING_OBJ: withNames(sameNames(
+		"len", "split", "trim", "upper", "lower", "replace", "starts_with",
+		"ends_with", "repeat", "pad_left", "pad_right", "format", "chars",
+		"contains", "index_of",
+	), map[string]string{
+		"to_int":   "int",
+		"to_float": "float",
+	}),
+	object.INTEGER_OBJ: withNames(sameNames(
+		"abs", "floor", "ceil", "round", "sqrt", "pow",
+	), map[string]string{
+		"to_int":   "int",
+		"to_float": "float",
+	}),
+	object.FLOAT_OBJ: withNames(sameNames(
+		"abs", "floor", "ceil", "round", "sqrt", "pow", "is_nan",
+	), map[string]string{
+		"to_int":   "int",
+		"to_float": "float",
+	}),
+	object.TIME_OBJ: map[string]string{
+		"format":    "format_time",
+		"unix_time": "unix_time",
+	},
+	object.DURATION_OBJ: map[string]string{
+		"ms": "duration_ms",
+	},
+}
+
+// commonMethods are available on every receiver type.
+var commonMethods = map[string]string{
+	"type":      "type",
+	"to_string": "str",
+	"to_json":   "json_encode",
+}
+
+func sameNames(names ...string) map[string]string {
+	methods := make(map[string]string, len(names))
+	for _, name := range names {
+		methods[name] = name
+	}
+	return methods
+}
+
+func withNames(methods map[string]string, renamed map[string]string) map[string]string {
+	for method, builtin := range renamed {
+		methods[method] = builtin
+	}
+	return methods
+}
+
+// method binds the builtin behind receiver.name to receiver.
+func (e *Evaluator) method(receiver object.Object, name string) (*object.Builtin, bool) {
+	builtinName, ok := methodTable[receiver.Type()][name]
+	if !ok {
+		builtinName, ok = commonMethods[name]
+	}
+	if !ok {
+		return nil, false
+	}
+	builtin, ok := e.builtins[builtinName]
+	if !ok {
+		return nil, false
+	}
+
+	return &object.Builtin{
+		Fn: func(ctx object.Context, args ...object.Object) object.Object {
+			return builtin.Fn(ctx, append([]object.Object{receiver}, args...)...)
+		},
+	}, true
+}
+
+// Methods lists, in sorted order, the methods callable on values of type t
+// with the builtins currently registered.
+func (e *Evaluator) Methods(t object.ObjectType) []string {
+	names := []string{}
+	for _, table := range []map[string]string{methodTable[t], commonMethods} {
+		for method, builtin := range table {
+			if _, ok := e.builtins[builtin]; ok {
+				names = append(names, method)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
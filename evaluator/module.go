@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/ast"
+	"github.com/arthurlee945/monkey.on/lexer"
+	"github.com/arthurlee945/monkey.on/object"
+	"github.com/arthurlee945/monkey.on/parser"
+)
+
+// MODULE_EXT is tried after the bare name when an import has no extension.
+const MODULE_EXT = ".mk"
+
+// ModuleLoader finds the source of an imported module. importer is the path
+// of the module containing the import, or "" for the main program. The
+// returned path identifies the module: two imports that resolve to the same
+// path share one evaluation.
+type ModuleLoader interface {
+	Load(name, importer string) (path string, source string, err error)
+}
+
+// SetModuleLoader enables `import`. Without a loader every import fails.
+func (e *Evaluator) SetModuleLoader(loader ModuleLoader) {
+	e.loader = loader
+}
+
+type fsModuleLoader struct {
+	fsys       fs.FS
+	searchPath []string
+}
+
+// NewModuleLoader loads modules from fsys. Names starting with ./ or ../ are
+// relative to the importing module; any other name is looked up in each
+// directory of searchPath in turn, which defaults to the root of fsys.
+func NewModuleLoader(fsys fs.FS, searchPath ...string) ModuleLoader {
+	if len(searchPath) == 0 {
+		searchPath = []string{"."}
+	}
+	return &fsModuleLoader{fsys: fsys, searchPath: searchPath}
+}
+
+func (l *fsModuleLoader) Load(name, importer string) (string, string, error) {
+	var candidates []string
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		candidates = []string{path.Join(path.Dir(importer), name)}
+	} else {
+		for _, dir := range l.searchPath {
+			candidates = append(candidates, path.Join(dir, name))
+		}
+	}
+
+	for _, candidate := range candidates {
+		files := []string{candidate}
+		if path.Ext(candidate) == "" {
+			files = append(files, candidate+MODULE_EXT)
+		}
+		for _, file := range files {
+			if !fs.ValidPath(file) {
+				return "", "", fmt.Errorf("module path %s is outside the loader root", file)
+			}
+			source, err := fs.ReadFile(l.fsys, file)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return "", "", err
+			}
+			return file, string(source), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("module not found")
+}
+
+func (e *Evaluator) evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	name := is.Path.Value
+	if e.loader == nil {
+		return newError("cannot import %q: no module loader configured", name)
+	}
+
+	importer := ""
+	if len(e.importing) > 0 {
+		importer = e.importing[len(e.importing)-1]
+	}
+	modulePath, source, err := e.loader.Load(name, importer)
+	if err != nil {
+		return newError("cannot import %q: %s", name, err)
+	}
+
+	module, errObj := e.loadModule(modulePath, source)
+	if errObj != nil {
+		return errObj
+	}
+
+	if is.Alias != nil {
+		return bind(env, is.Alias.Value, module, false)
+	}
+	for _, ident := range is.Names {
+		value, ok := module.Exports[ident.Value]
+		if !ok {
+			return newError("module %q has no export %s", module.Name, ident.Value)
+		}
+		if err := bind(env, ident.Value, value, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadModule evaluates a module the first time it is imported, in an
+// environment of its own, and caches the result.
+func (e *Evaluator) loadModule(modulePath, source string) (*object.Module, object.Object) {
+	if module, ok := e.modules[modulePath]; ok {
+		return module, nil
+	}
+	for idx, loading := range e.importing {
+		if loading == modulePath {
+			chain := append(append([]string{}, e.importing[idx:]...), modulePath)
+			return nil, newError("import cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, newError("cannot parse module %q: %s", modulePath, strings.Join(p.Errors(), "; "))
+	}
+
+	e.importing = append(e.importing, modulePath)
+	defer func() { e.importing = e.importing[:len(e.importing)-1] }()
+
+	env := object.NewEnvironment()
+	if result := e.Eval(program, env); isError(result) {
+		return nil, result
+	}
+
+	module := &object.Module{Name: modulePath, Exports: make(map[string]object.Object)}
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			for _, ident := range export.Statement.Names() {
+				module.Exports[ident.Value], _ = env.Get(ident.Value)
+			}
+		}
+	}
+	e.modules[modulePath] = module
+
+	return module, nil
+}
@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"github.com/arthurlee945/monkey.on/ast"
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// bind sets name in env, as a constant if constant is set. It returns the
+// error for a redeclared constant and nil otherwise.
+func bind(env *object.Environment, name string, value object.Object, constant bool) object.Object {
+	var result object.Object
+	if constant {
+		result = env.SetConst(name, value)
+	} else {
+		result = env.Set(name, value)
+	}
+	if isError(result) {
+		return result
+	}
+	return nil
+}
+
+// bindPattern destructures value into env following pattern. It returns an
+// error when value does not have the shape the pattern asks for, and nil
+// otherwise; a match arm whose pattern fails to bind is skipped.
+func (e *Evaluator) bindPattern(pattern ast.Pattern, value object.Object, env *object.Environment, constant bool) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value == "_" {
+			return nil
+		}
+		return bind(env, pattern.Value, value, constant)
+	case *ast.LiteralPattern:
+		literal := e.Eval(pattern.Value, env)
+		if isError(literal) {
+			return literal
+		}
+		if !objectsEqual(literal, value) {
+			return newError("cannot destructure %s: expected %s", value.Inspect(), literal.Inspect())
+		}
+		return nil
+	case *ast.ArrayPattern:
+		return e.bindArrayPattern(pattern, value, env, constant)
+	case *ast.HashPattern:
+		return e.bindHashPattern(pattern, value, env, constant)
+	default:
+		return newError("unknown pattern: %s", pattern)
+	}
+}
+
+func (e *Evaluator) bindArrayPattern(pattern *ast.ArrayPattern, value object.Object, env *object.Environment, constant bool) object.Object {
+	array, ok := value.(*object.Array)
+	if !ok {
+		return newError("cannot destructure %s as an array", value.Type())
+	}
+	count := len(pattern.Elements)
+	if len(array.Elements) < count || pattern.Rest == nil && len(array.Elements) != count {
+		return newError("cannot destructure array of %d elements into %s", len(array.Elements), pattern)
+	}
+
+	for idx, el := range pattern.Elements {
+		if err := e.bindPattern(el, array.Elements[idx], env, constant); err != nil {
+			return err
+		}
+	}
+	if pattern.Rest != nil {
+		rest := make([]object.Object, len(array.Elements)-count)
+		copy(rest, array.Elements[count:])
+		return bind(env, pattern.Rest.Value, &object.Array{Elements: rest}, constant)
+	}
+
+	return nil
+}
+
+func (e *Evaluator) bindHashPattern(pattern *ast.HashPattern, value object.Object, env *object.Environment, constant bool) object.Object {
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return newError("cannot destructure %s as a hash", value.Type())
+	}
+
+	taken := make(map[object.HashKey]bool, len(pattern.Pairs))
+	for _, pair := range pattern.Pairs {
+		var key object.Object = &object.String{Value: pair.Key.String()}
+		if _, ok := pair.Key.(*ast.Identifier); !ok {
+			key = e.Eval(pair.Key, env)
+		}
+		if isError(key) {
+			return key
+		}
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+		hashKey := hashable.HashKey()
+		entry, ok := hash.Pairs[hashKey]
+		if !ok {
+			if str, ok := key.(*object.String); ok {
+				return newError("cannot destructure hash without key %q", str.Value)
+			}
+			return newError("cannot destructure hash without key %s", key.Inspect())
+		}
+		taken[hashKey] = true
+		if err := e.bindPattern(pair.Value, entry.Value, env, constant); err != nil {
+			return err
+		}
+	}
+	if pattern.Rest != nil {
+		rest := make(map[object.HashKey]object.HashPair)
+		for key, entry := range hash.Pairs {
+			if !taken[key] {
+				rest[key] = entry
+			}
+		}
+		return bind(env, pattern.Rest.Value, &object.Hash{Pairs: rest}, constant)
+	}
+
+	return nil
+}
+
+// evalMatchExpression binds each arm's pattern in an environment of its own
+// and evaluates the first arm that binds and passes its guard.
+func (e *Evaluator) evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := e.Eval(me.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+		if err := e.bindPattern(arm.Pattern, subject, armEnv, false); err != nil {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := e.Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		return e.Eval(arm.Body, armEnv)
+	}
+
+	return newError("no match for %s", subject.Inspect())
+}
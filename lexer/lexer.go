@@ -66,10 +66,15 @@ func (l *Lexer) NextToken() token.Token {
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
+	case '`':
+		tok.Type = token.TEMPLATE
+		tok.Literal = l.readTemplate()
 	//operator
 	case '=':
 		if l.peekChar() == '=' {
 			tok = l.makeTwoCharToken(token.EQ)
+		} else if l.peekChar() == '>' {
+			tok = l.makeTwoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
@@ -84,7 +89,17 @@ func (l *Lexer) NextToken() token.Token {
 	case '-':
 		tok = newToken(token.MINUS, l.ch)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '*' {
+			tok = l.makeTwoCharToken(token.POWER)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '>' {
+			tok = l.makeTwoCharToken(token.PIPE)
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case '/':
 		tok = newToken(token.SLASH, l.ch)
 	case '%':
@@ -117,7 +132,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Type = token.EOF
 	default:
 		if l.isLetter() {
-			tok.Literal = l.readInput(l.isLetter)
+			tok.Literal = l.readInput(l.isIdentifier)
 			tok.Type = token.LookupIndentifier(tok.Literal)
 			return tok
 		} else if l.isNumber() {
@@ -128,6 +143,14 @@ func (l *Lexer) NextToken() token.Token {
 				tok.Type = token.INT
 			}
 			return tok
+		} else if strings.HasPrefix(l.input[l.position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.ch == '.' {
+			// a dot not followed by a digit is member access such as obj.name;
+			// isNumber has already taken numbers such as .5
+			tok = newToken(token.DOT, l.ch)
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -140,6 +163,12 @@ func (l *Lexer) isLetter() bool {
 	return ('a' <= l.ch && 'z' >= l.ch) || ('A' <= l.ch && 'Z' >= l.ch) || l.ch == '_'
 }
 
+// isIdentifier also accepts digits, which may follow the first letter of a
+// name as in log10.
+func (l *Lexer) isIdentifier() bool {
+	return l.isLetter() || '0' <= l.ch && l.ch <= '9'
+}
+
 func (l *Lexer) isNumber() bool {
 	return '0' <= l.ch && l.ch <= '9' || l.ch == '.' && '0' <= l.peekChar() && l.peekChar() <= '9'
 }
@@ -155,6 +184,19 @@ func (l *Lexer) readString() string {
 	return l.input[position:l.position]
 }
 
+// readTemplate returns the raw body of a backtick string; the parser splits
+// out the ${...} expressions.
+func (l *Lexer) readTemplate() string {
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '`' || l.ch == 0 {
+			break
+		}
+	}
+	return l.input[position:l.position]
+}
+
 func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
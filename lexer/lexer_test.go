@@ -38,6 +38,14 @@ func TestNextToken(t *testing.T) {
 	"monkey paw"
 	[1, 23];
 	{"monkey" : "paw"}
+	` + "`hi ${name}!`" + `
+	2 ** log10;
+	import export
+	lib.pi .5
+	xs |> len
+	[a, ...b]
+	match (a) { _ => b }
+	const
 	`
 
 	//tests := []struct{expectedType token.TokenType expectedLiteral string}
@@ -152,6 +160,37 @@ func TestNextToken(t *testing.T) {
 		{token.STRING, "paw"},
 		{token.RBRACE, "}"},
 
+		{token.TEMPLATE, "hi ${name}!"},
+		{token.INT, "2"},
+		{token.POWER, "**"},
+		{token.IDENT, "log10"},
+		{token.SEMICOLON, ";"},
+		{token.IMPORT, "import"},
+		{token.EXPORT, "export"},
+		{token.IDENT, "lib"},
+		{token.DOT, "."},
+		{token.IDENT, "pi"},
+		{token.FLOAT, ".5"},
+		{token.IDENT, "xs"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "len"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "b"},
+		{token.RBRACKET, "]"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "b"},
+		{token.RBRACE, "}"},
+		{token.CONST, "const"},
+
 		{token.EOF, ""},
 	}
 
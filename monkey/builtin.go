@@ -0,0 +1,132 @@
+package monkey
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/arthurlee945/monkey.on/evaluator"
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+// Register exposes an arbitrary Go func to scripts as a builtin named name.
+// Argument count and types are checked on every call, and a non-nil error
+// returned as the func's last result becomes a Monkey error:
+//
+//	interp.Register("fetch", func(url string, retries int) (string, error) { ... })
+func (i *Interpreter) Register(name string, fn interface{}) error {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return fmt.Errorf("cannot register %s: expected a func, got %T", name, fn)
+	}
+
+	i.evaluator.Register(name, i.wrapFunc(name, value).Fn)
+	return nil
+}
+
+// wrapFunc turns a Go func into a builtin. Results other than a trailing
+// error are converted back to Monkey; several of them come back as an array.
+func (i *Interpreter) wrapFunc(name string, fn reflect.Value) *object.Builtin {
+	typ := fn.Type()
+	numIn := typ.NumIn()
+	returnsError := typ.NumOut() > 0 && typ.Out(typ.NumOut()-1) == errorType
+
+	return &object.Builtin{Fn: func(ctx object.Context, args ...object.Object) (result object.Object) {
+		if typ.IsVariadic() && len(args) < numIn-1 {
+			return newError("wrong number of arguments. got=%d, expected at least %d", len(args), numIn-1)
+		}
+		if !typ.IsVariadic() && len(args) != numIn {
+			return newError("wrong number of arguments. got=%d, expected=%d", len(args), numIn)
+		}
+
+		in := make([]reflect.Value, len(args))
+		for idx, arg := range args {
+			paramType := typ.In(min(idx, numIn-1))
+			if typ.IsVariadic() && idx >= numIn-1 {
+				paramType = paramType.Elem()
+			}
+
+			value, err := i.toValue(arg, paramType)
+			if err != nil {
+				return argumentError(name, idx, paramType, arg, err)
+			}
+			in[idx] = value
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				result = newError("%s panicked: %v", describe(name), r)
+			}
+		}()
+
+		out := fn.Call(in)
+		if returnsError {
+			if err := out[len(out)-1]; !err.IsNil() {
+				return newError("%s", err.Interface().(error))
+			}
+			out = out[:len(out)-1]
+		}
+
+		switch len(out) {
+		case 0:
+			return evaluator.NULL
+		case 1:
+			return i.resultToObject(out[0])
+		default:
+			elements := make([]object.Object, len(out))
+			for idx, value := range out {
+				elements[idx] = i.resultToObject(value)
+			}
+			return &object.Array{Elements: elements}
+		}
+	}}
+}
+
+func (i *Interpreter) resultToObject(result reflect.Value) object.Object {
+	obj, err := i.toObject(result)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return obj
+}
+
+// argumentError reports a failed argument conversion in the same shape the
+// evaluator's own builtins use, falling back to the conversion error when the
+// Monkey type was right but the value did not fit.
+func argumentError(name string, idx int, typ reflect.Type, arg object.Object, err error) *object.Error {
+	expected := objectTypeOf(typ)
+	if expected == "" || expected == arg.Type() || expected == object.FLOAT_OBJ && arg.Type() == object.INTEGER_OBJ {
+		return newError("argument %d to %s: %s", idx+1, describe(name), err)
+	}
+	return newError("argument %d to %s must be %s, got %s", idx+1, describe(name), expected, arg.Type())
+}
+
+// objectTypeOf names the Monkey type a Go type is converted from, or "" when
+// several Monkey types are accepted.
+func objectTypeOf(typ reflect.Type) object.ObjectType {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return object.BOOLEAN_OBJ
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.INTEGER_OBJ
+	case reflect.Float32, reflect.Float64:
+		return object.FLOAT_OBJ
+	case reflect.String:
+		return object.STRING_OBJ
+	case reflect.Slice, reflect.Array:
+		return object.ARRAY_OBJ
+	case reflect.Map, reflect.Struct:
+		return object.HASH_OBJ
+	case reflect.Func:
+		return object.FUNCTION_OBJ
+	default:
+		return ""
+	}
+}
+
+func describe(name string) string {
+	if name == "" {
+		return "builtin"
+	}
+	return "`" + name + "`"
+}
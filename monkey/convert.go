@@ -0,0 +1,422 @@
+package monkey
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/arthurlee945/monkey.on/evaluator"
+	"github.com/arthurlee945/monkey.on/object"
+)
+
+var (
+	objectType   = reflect.TypeOf((*object.Object)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// ToObject converts a Go value into its Monkey counterpart. Structs become
+// hashes keyed by field name (or the `monkey` struct tag) and funcs become
+// builtins.
+func (i *Interpreter) ToObject(value interface{}) (object.Object, error) {
+	if value == nil {
+		return evaluator.NULL, nil
+	}
+	if obj, ok := value.(object.Object); ok {
+		return obj, nil
+	}
+	return i.toObject(reflect.ValueOf(value))
+}
+
+// visit identifies a pointer, map or slice that is being converted. Slices
+// also record their length since a slice and a subslice share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func (i *Interpreter) toObject(v reflect.Value) (object.Object, error) {
+	return i.convert(v, make(map[visit]bool))
+}
+
+// convert does the work of toObject. visiting holds the pointers, maps and
+// slices on the current path so a value that contains itself is reported
+// instead of recursing forever.
+func (i *Interpreter) convert(v reflect.Value, visiting map[visit]bool) (object.Object, error) {
+	if !v.IsValid() {
+		return evaluator.NULL, nil
+	}
+	if v.Type().Implements(objectType) && v.CanInterface() {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return v.Interface().(object.Object), nil
+	}
+	switch v.Type() {
+	case timeType:
+		return &object.Time{Value: v.Interface().(time.Time)}, nil
+	case durationType:
+		return &object.Duration{Value: time.Duration(v.Int())}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if !v.IsNil() {
+			key := visit{ptr: v.Pointer(), typ: v.Type()}
+			if v.Kind() == reflect.Slice {
+				key.len = v.Len()
+			}
+			if visiting[key] {
+				return nil, fmt.Errorf("cannot convert %s: value contains itself", v.Type())
+			}
+			visiting[key] = true
+			defer delete(visiting, key)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > 1<<63-1 {
+			return nil, fmt.Errorf("cannot convert %d to INTEGER: overflows int64", v.Uint())
+		}
+		return &object.Integer{Value: int64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]object.Object, v.Len())
+		for idx := range elements {
+			el, err := i.convert(v.Index(idx), visiting)
+			if err != nil {
+				return nil, err
+			}
+			elements[idx] = el
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		pairs := make(map[object.HashKey]object.HashPair)
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := i.convert(iter.Key(), visiting)
+			if err != nil {
+				return nil, err
+			}
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+			}
+			value, err := i.convert(iter.Value(), visiting)
+			if err != nil {
+				return nil, err
+			}
+			pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+		}
+		return &object.Hash{Pairs: pairs}, nil
+	case reflect.Struct:
+		pairs := make(map[object.HashKey]object.HashPair)
+		for idx := 0; idx < v.NumField(); idx++ {
+			name, ok := fieldName(v.Type().Field(idx))
+			if !ok {
+				continue
+			}
+			value, err := i.convert(v.Field(idx), visiting)
+			if err != nil {
+				return nil, err
+			}
+			key := &object.String{Value: name}
+			pairs[key.HashKey()] = object.HashPair{Key: key, Value: value}
+		}
+		return &object.Hash{Pairs: pairs}, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return i.convert(v.Elem(), visiting)
+	case reflect.Func:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return i.wrapFunc("", v), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a monkey object", v.Type())
+	}
+}
+
+// FromObject converts a Monkey object into plain Go values: int64, float64,
+// string, bool, nil, time.Time, time.Duration, []interface{} and maps.
+// Hashes with only string keys become map[string]interface{}. Functions are
+// returned unchanged.
+func FromObject(obj object.Object) interface{} {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Integer:
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Time:
+		return obj.Value
+	case *object.Duration:
+		return obj.Value
+	case *object.Array:
+		elements := make([]interface{}, len(obj.Elements))
+		for idx, el := range obj.Elements {
+			elements[idx] = FromObject(el)
+		}
+		return elements
+	case *object.Hash:
+		stringKeys := make(map[string]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				break
+			}
+			stringKeys[key.Value] = FromObject(pair.Value)
+		}
+		if len(stringKeys) == len(obj.Pairs) {
+			return stringKeys
+		}
+
+		anyKeys := make(map[interface{}]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			anyKeys[FromObject(pair.Key)] = FromObject(pair.Value)
+		}
+		return anyKeys
+	default:
+		return obj
+	}
+}
+
+// Decode converts obj into the Go value target points to, following the same
+// rules as ToObject in reverse.
+func (i *Interpreter) Decode(obj object.Object, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+
+	value, err := i.toValue(obj, ptr.Elem().Type())
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(value)
+	return nil
+}
+
+func (i *Interpreter) toValue(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	if obj == nil {
+		obj = evaluator.NULL
+	}
+	if typ.Kind() == reflect.Interface {
+		if typ.NumMethod() == 0 {
+			native := FromObject(obj)
+			if native == nil {
+				return reflect.Zero(typ), nil
+			}
+			return reflect.ValueOf(native), nil
+		}
+		if reflect.TypeOf(obj).Implements(typ) {
+			value := reflect.New(typ).Elem()
+			value.Set(reflect.ValueOf(obj))
+			return value, nil
+		}
+	}
+	if obj == evaluator.NULL {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func, reflect.Interface:
+			return reflect.Zero(typ), nil
+		}
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		elem, err := i.toValue(obj, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value := reflect.New(typ.Elem())
+		value.Elem().Set(elem)
+		return value, nil
+	}
+
+	value := reflect.New(typ).Elem()
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		if typ.Kind() == reflect.Bool {
+			value.SetBool(obj.Value)
+			return value, nil
+		}
+	case *object.Integer:
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.OverflowInt(obj.Value) {
+				return value, fmt.Errorf("cannot convert %d to %s: out of range", obj.Value, typ)
+			}
+			value.SetInt(obj.Value)
+			return value, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if obj.Value < 0 || value.OverflowUint(uint64(obj.Value)) {
+				return value, fmt.Errorf("cannot convert %d to %s: out of range", obj.Value, typ)
+			}
+			value.SetUint(uint64(obj.Value))
+			return value, nil
+		case reflect.Float32, reflect.Float64:
+			value.SetFloat(float64(obj.Value))
+			return value, nil
+		}
+	case *object.Float:
+		if typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64 {
+			value.SetFloat(obj.Value)
+			return value, nil
+		}
+	case *object.String:
+		if typ.Kind() == reflect.String {
+			value.SetString(obj.Value)
+			return value, nil
+		}
+	case *object.Time:
+		if typ == timeType {
+			value.Set(reflect.ValueOf(obj.Value))
+			return value, nil
+		}
+	case *object.Duration:
+		if typ == durationType {
+			value.SetInt(int64(obj.Value))
+			return value, nil
+		}
+	case *object.Array:
+		switch typ.Kind() {
+		case reflect.Slice:
+			value = reflect.MakeSlice(typ, len(obj.Elements), len(obj.Elements))
+		case reflect.Array:
+			if typ.Len() != len(obj.Elements) {
+				return value, fmt.Errorf("cannot convert ARRAY of length %d to %s", len(obj.Elements), typ)
+			}
+		default:
+			return value, conversionError(obj, typ)
+		}
+		for idx, el := range obj.Elements {
+			elValue, err := i.toValue(el, typ.Elem())
+			if err != nil {
+				return value, err
+			}
+			value.Index(idx).Set(elValue)
+		}
+		return value, nil
+	case *object.Hash:
+		switch typ.Kind() {
+		case reflect.Map:
+			value = reflect.MakeMapWithSize(typ, len(obj.Pairs))
+			for _, pair := range obj.Pairs {
+				key, err := i.toValue(pair.Key, typ.Key())
+				if err != nil {
+					return value, err
+				}
+				elValue, err := i.toValue(pair.Value, typ.Elem())
+				if err != nil {
+					return value, err
+				}
+				value.SetMapIndex(key, elValue)
+			}
+			return value, nil
+		case reflect.Struct:
+			for idx := 0; idx < typ.NumField(); idx++ {
+				name, ok := fieldName(typ.Field(idx))
+				if !ok {
+					continue
+				}
+				pair, ok := obj.Pairs[(&object.String{Value: name}).HashKey()]
+				if !ok {
+					continue
+				}
+				field, err := i.toValue(pair.Value, typ.Field(idx).Type)
+				if err != nil {
+					return value, fmt.Errorf("field %s: %w", name, err)
+				}
+				value.Field(idx).Set(field)
+			}
+			return value, nil
+		}
+	case *object.Function, *object.Builtin:
+		if typ.Kind() == reflect.Func {
+			return i.makeFunc(obj, typ), nil
+		}
+	}
+
+	return value, conversionError(obj, typ)
+}
+
+// makeFunc builds a Go func of type typ that applies a Monkey function. A
+// trailing error result receives Monkey errors; without one they panic.
+func (i *Interpreter) makeFunc(fn object.Object, typ reflect.Type) reflect.Value {
+	return reflect.MakeFunc(typ, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, typ.NumOut())
+		for idx := range out {
+			out[idx] = reflect.Zero(typ.Out(idx))
+		}
+		fail := func(err error) []reflect.Value {
+			if len(out) == 0 || typ.Out(len(out)-1) != errorType {
+				panic(err)
+			}
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		args := make([]object.Object, len(in))
+		for idx, arg := range in {
+			obj, err := i.toObject(arg)
+			if err != nil {
+				return fail(err)
+			}
+			args[idx] = obj
+		}
+
+		result, err := checkError(i.evaluator.Apply(fn, args...))
+		if err != nil {
+			return fail(err)
+		}
+		if len(out) > 0 && typ.Out(0) != errorType {
+			value, err := i.toValue(result, typ.Out(0))
+			if err != nil {
+				return fail(err)
+			}
+			out[0] = value
+		}
+		return out
+	})
+}
+
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	switch tag := field.Tag.Get("monkey"); tag {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return tag, true
+	}
+}
+
+func conversionError(obj object.Object, typ reflect.Type) error {
+	return fmt.Errorf("cannot convert %s to %s", obj.Type(), typ)
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
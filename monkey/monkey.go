@@ -0,0 +1,152 @@
+// Package monkey embeds the Monkey interpreter in Go programs.
+//
+//	interp := monkey.New(evaluator.PURE_PROFILE)
+//	interp.Set("name", "monkey")
+//	greeting, err := interp.Eval(`"Hello " + name`)
+package monkey
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/arthurlee945/monkey.on/evaluator"
+	"github.com/arthurlee945/monkey.on/lexer"
+	"github.com/arthurlee945/monkey.on/object"
+	"github.com/arthurlee945/monkey.on/parser"
+)
+
+// Interpreter keeps a global environment alive between calls to Eval, so
+// bindings made by one script are visible to the next.
+type Interpreter struct {
+	evaluator *evaluator.Evaluator
+	env       *object.Environment
+}
+
+func New(profile evaluator.Profile) *Interpreter {
+	return &Interpreter{
+		evaluator: evaluator.New(profile),
+		env:       object.NewEnvironment(),
+	}
+}
+
+// ParseError carries every message reported by the parser.
+type ParseError struct {
+	Messages []string
+}
+
+func (pe *ParseError) Error() string {
+	return "parser errors:\n\t" + strings.Join(pe.Messages, "\n\t")
+}
+
+// RuntimeError is returned when a script evaluates to a Monkey error.
+type RuntimeError struct {
+	Message string
+}
+
+func (re *RuntimeError) Error() string {
+	return re.Message
+}
+
+// Evaluator exposes the underlying evaluator so hosts can register builtins
+// or redirect output.
+func (i *Interpreter) Evaluator() *evaluator.Evaluator {
+	return i.evaluator
+}
+
+// Eval parses and evaluates src, returning the result as a Go value.
+func (i *Interpreter) Eval(src string) (interface{}, error) {
+	obj, err := i.EvalObject(src)
+	if err != nil {
+		return nil, err
+	}
+	return FromObject(obj), nil
+}
+
+// EvalObject is Eval without converting the result back to Go.
+func (i *Interpreter) EvalObject(src string) (object.Object, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Messages: p.Errors()}
+	}
+
+	return checkError(i.evaluator.Eval(program, i.env))
+}
+
+// Set binds a Go value to name in the global environment. It fails if a
+// script declared name as a constant.
+func (i *Interpreter) Set(name string, value interface{}) error {
+	if fn := reflect.ValueOf(value); fn.Kind() == reflect.Func && !fn.IsNil() {
+		_, err := checkError(i.env.Set(name, i.wrapFunc(name, fn)))
+		return err
+	}
+
+	obj, err := i.ToObject(value)
+	if err != nil {
+		return err
+	}
+	_, err = checkError(i.env.Set(name, obj))
+	return err
+}
+
+// Get looks up name in the global environment and converts it to Go.
+func (i *Interpreter) Get(name string) (interface{}, error) {
+	obj, err := i.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromObject(obj), nil
+}
+
+// GetAs looks up name and decodes it into the value target points to.
+func (i *Interpreter) GetAs(name string, target interface{}) error {
+	obj, err := i.lookup(name)
+	if err != nil {
+		return err
+	}
+	return i.Decode(obj, target)
+}
+
+// Call invokes the function bound to name with args converted to Monkey.
+func (i *Interpreter) Call(name string, args ...interface{}) (interface{}, error) {
+	fn, err := i.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]object.Object, len(args))
+	for idx, arg := range args {
+		obj, err := i.ToObject(arg)
+		if err != nil {
+			return nil, err
+		}
+		objs[idx] = obj
+	}
+
+	result, err := checkError(i.evaluator.Apply(fn, objs...))
+	if err != nil {
+		return nil, err
+	}
+	return FromObject(result), nil
+}
+
+func (i *Interpreter) lookup(name string) (object.Object, error) {
+	if obj, ok := i.env.Get(name); ok {
+		return obj, nil
+	}
+	if builtin, ok := i.evaluator.Builtin(name); ok {
+		return builtin, nil
+	}
+	if constant, ok := i.evaluator.Constant(name); ok {
+		return constant, nil
+	}
+	return nil, fmt.Errorf("identifier not found: %s", name)
+}
+
+func checkError(obj object.Object) (object.Object, error) {
+	if errObj, ok := obj.(*object.Error); ok {
+		return nil, &RuntimeError{Message: errObj.Message}
+	}
+	return obj, nil
+}
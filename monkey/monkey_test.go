@@ -0,0 +1,329 @@
+package monkey
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arthurlee945/monkey.on/evaluator"
+)
+
+type monkeyInfo struct {
+	Name    string
+	Age     int    `monkey:"age"`
+	Secret  string `monkey:"-"`
+	bananas int
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"5 * 5", int64(25)},
+		{"2.5 * 2", float64(5)},
+		{`"monkey" + " paw"`, "monkey paw"},
+		{"1 < 2", true},
+		{"if (false) { 1 }", nil},
+		{"[1, 2 * 2, 3]", []interface{}{int64(1), int64(4), int64(3)}},
+		{`{"one": 1, "two": 2}`, map[string]interface{}{"one": int64(1), "two": int64(2)}},
+		{`{1: "one", "two": 2}`, map[interface{}]interface{}{int64(1): "one", "two": int64(2)}},
+	}
+
+	for _, tt := range tests {
+		interp := New(evaluator.PURE_PROFILE)
+		result, err := interp.Eval(tt.input)
+		if err != nil {
+			t.Fatalf("Eval(%q) returned error: %s", tt.input, err)
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Eval(%q) wrong result. expected=%#v, got=%#v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+
+	_, err := interp.Eval("let = 5;")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected ParseError. got=%T (%v)", err, err)
+	}
+
+	_, err = interp.Eval("5 + true")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Message)
+	}
+
+	if _, err := interp.Eval("const limit = 3;"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = interp.Set("limit", 4)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Message != "cannot redeclare constant limit" {
+		t.Errorf("expected constant redeclaration error. got=%v", err)
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+
+	values := map[string]interface{}{
+		"count":   uint8(8),
+		"ratio":   float32(0.5),
+		"tags":    []string{"a", "b"},
+		"scores":  map[string]int{"monkey": 10},
+		"info":    &monkeyInfo{Name: "Momo", Age: 3, Secret: "banana", bananas: 2},
+		"nothing": nil,
+	}
+	for name, value := range values {
+		if err := interp.Set(name, value); err != nil {
+			t.Fatalf("Set(%q) returned error: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"count + 2", int64(10)},
+		{"ratio * 4", float64(2)},
+		{"tags[1]", "b"},
+		{`scores["monkey"]`, int64(10)},
+		{`info["Name"]`, "Momo"},
+		{`info["age"]`, int64(3)},
+		{`info["Secret"]`, nil},
+		{`info["bananas"]`, nil},
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		result, err := interp.Eval(tt.input)
+		if err != nil {
+			t.Fatalf("Eval(%q) returned error: %s", tt.input, err)
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Eval(%q) wrong result. expected=%#v, got=%#v", tt.input, tt.expected, result)
+		}
+	}
+
+	if _, err := interp.Eval(`let monkey = {"Name": "Abu", "age": 7};`); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	var decoded monkeyInfo
+	if err := interp.GetAs("monkey", &decoded); err != nil {
+		t.Fatalf("GetAs returned error: %s", err)
+	}
+	if decoded.Name != "Abu" || decoded.Age != 7 {
+		t.Errorf("decoded struct is wrong. got=%+v", decoded)
+	}
+
+	if _, err := interp.Get("undefined"); err == nil {
+		t.Errorf("expected error for unknown identifier")
+	}
+}
+
+type monkeyNode struct {
+	Name string
+	Next *monkeyNode
+}
+
+func TestCyclicConversion(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+
+	node := &monkeyNode{Name: "a"}
+	node.Next = node
+	hash := map[string]interface{}{}
+	hash["self"] = hash
+	slice := []interface{}{nil}
+	slice[0] = slice
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{node, "cannot convert *monkey.monkeyNode: value contains itself"},
+		{hash, "cannot convert map[string]interface {}: value contains itself"},
+		{slice, "cannot convert []interface {}: value contains itself"},
+	}
+	for _, tt := range tests {
+		err := interp.Set("cyclic", tt.value)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%v", tt.expected, err)
+		}
+	}
+
+	shared := &monkeyNode{Name: "shared"}
+	if err := interp.Set("pair", []*monkeyNode{shared, shared}); err != nil {
+		t.Fatalf("Set returned error for shared pointer: %s", err)
+	}
+	result, err := interp.Eval(`pair[1]["Name"]`)
+	if err != nil || result != "shared" {
+		t.Errorf("wrong result. expected=%q, got=%#v (%v)", "shared", result, err)
+	}
+}
+
+func TestTimeConversion(t *testing.T) {
+	interp := New(evaluator.FULL_PROFILE)
+	start := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	interp.Set("start", start)
+	interp.Set("timeout", 90*time.Second)
+
+	result, err := interp.Eval("start + timeout * 2")
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result != start.Add(3*time.Minute) {
+		t.Errorf("wrong time. expected=%v, got=%v", start.Add(3*time.Minute), result)
+	}
+
+	interp.Eval(`let window = {"from": start, "length": duration("1h")};`)
+	var window struct {
+		From   time.Time     `monkey:"from"`
+		Length time.Duration `monkey:"length"`
+	}
+	if err := interp.GetAs("window", &window); err != nil {
+		t.Fatalf("GetAs returned error: %s", err)
+	}
+	if !window.From.Equal(start) || window.Length != time.Hour {
+		t.Errorf("decoded window is wrong. got=%+v", window)
+	}
+}
+
+func TestCall(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+	if _, err := interp.Eval("let add = fn(x, y) { x + y };"); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+
+	result, err := interp.Call("add", 3, 4)
+	if err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+	if result != int64(7) {
+		t.Errorf("wrong result. expected=7, got=%#v", result)
+	}
+
+	result, err = interp.Call("len", "monkey")
+	if err != nil {
+		t.Fatalf("Call returned error: %s", err)
+	}
+	if result != int64(6) {
+		t.Errorf("wrong result. expected=6, got=%#v", result)
+	}
+
+	if _, err := interp.Call("add", 1); err == nil || err.Error() != "wrong number of arguments. got=1, expected=2" {
+		t.Errorf("wrong error. got=%v", err)
+	}
+}
+
+func TestFunctionConversion(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+	interp.Set("shout", func(s string, times int) string {
+		return strings.Repeat(strings.ToUpper(s), times)
+	})
+
+	result, err := interp.Eval(`shout("ook", 2)`)
+	if err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	if result != "OOKOOK" {
+		t.Errorf("wrong result. got=%#v", result)
+	}
+
+	_, err = interp.Eval(`shout(1, 2)`)
+	if err == nil || err.Error() != "argument 1 to `shout` must be STRING, got INTEGER" {
+		t.Errorf("wrong error. got=%v", err)
+	}
+
+	if _, err := interp.Eval("let double = fn(x) { x * 2 };"); err != nil {
+		t.Fatalf("Eval returned error: %s", err)
+	}
+	var double func(int) (int, error)
+	if err := interp.GetAs("double", &double); err != nil {
+		t.Fatalf("GetAs returned error: %s", err)
+	}
+	if n, err := double(21); err != nil || n != 42 {
+		t.Errorf("wrong result. expected=42, got=%d (%v)", n, err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	interp := New(evaluator.PURE_PROFILE)
+
+	registered := map[string]interface{}{
+		"divide": func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		},
+		"sum": func(nums ...int) int {
+			total := 0
+			for _, n := range nums {
+				total += n
+			}
+			return total
+		},
+		"label": func(prefix string, ids ...int) string {
+			return fmt.Sprint(prefix, ids)
+		},
+		"byte":  func(b uint8) uint8 { return b },
+		"split": func(s string) (string, string) { return s[:1], s[1:] },
+		"boom":  func() { panic("no bananas") },
+		"check": func(ok bool) error {
+			if !ok {
+				return errors.New("check failed")
+			}
+			return nil
+		},
+	}
+	for name, fn := range registered {
+		if err := interp.Register(name, fn); err != nil {
+			t.Fatalf("Register(%q) returned error: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"divide(9, 2)", float64(4.5)},
+		{"divide(1, 0)", "division by zero"},
+		{"divide(1)", "wrong number of arguments. got=1, expected=2"},
+		{`divide("1", 2)`, "argument 1 to `divide` must be FLOAT, got STRING"},
+		{"sum()", int64(0)},
+		{"sum(1, 2, 3)", int64(6)},
+		{`sum(1, "2")`, "argument 2 to `sum` must be INTEGER, got STRING"},
+		{`label("ids", 1, 2)`, "ids[1 2]"},
+		{"label()", "wrong number of arguments. got=0, expected at least 1"},
+		{"byte(300)", "argument 1 to `byte`: cannot convert 300 to uint8: out of range"},
+		{`split("monkey")`, []interface{}{"m", "onkey"}},
+		{"boom()", "`boom` panicked: no bananas"},
+		{"check(true)", nil},
+		{"check(false)", "check failed"},
+	}
+
+	for _, tt := range tests {
+		result, err := interp.Eval(tt.input)
+		if err != nil {
+			if err.Error() != tt.expected {
+				t.Errorf("Eval(%q) wrong error. expected=%#v, got=%q", tt.input, tt.expected, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Eval(%q) wrong result. expected=%#v, got=%#v", tt.input, tt.expected, result)
+		}
+	}
+
+	if err := interp.Register("nope", 5); err == nil {
+		t.Errorf("expected error registering a non-func")
+	}
+}
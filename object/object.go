@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/arthurlee945/monkey.on/ast"
 )
@@ -23,9 +25,18 @@ const (
 	ERROR_OBJ    = "ERROR"
 	BUILTIN_OBJ  = "BUILTIN"
 	HASH_OBJ     = "HASH"
+	TIME_OBJ     = "TIME"
+	DURATION_OBJ = "DURATION"
+	MODULE_OBJ   = "MODULE"
 )
 
-type BuiltinFunction func(args ...Object) Object
+// Context is handed to every builtin call so builtins can call back into
+// the evaluator, e.g. to apply a Monkey function passed as an argument.
+type Context interface {
+	Apply(fn Object, args ...Object) Object
+}
+
+type BuiltinFunction func(ctx Context, args ...Object) Object
 
 type Object interface {
 	Type() ObjectType
@@ -42,7 +53,8 @@ type HashPair struct {
 	Value Object
 }
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	Pairs  map[HashKey]HashPair
+	Frozen bool
 }
 
 type Hashable interface {
@@ -54,7 +66,7 @@ func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.SortedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 
@@ -65,6 +77,58 @@ func (h *Hash) Inspect() string {
 	return out.String()
 }
 
+// SortedPairs returns the pairs ordered by key so that iteration and output
+// are stable: numbers first by value, then strings, then booleans.
+func (h *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return keyLess(pairs[i].Key, pairs[j].Key)
+	})
+	return pairs
+}
+
+func keyLess(a, b Object) bool {
+	rankA, rankB := keyRank(a), keyRank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		if b, ok := b.(*Integer); ok {
+			return a.Value < b.Value
+		}
+		return float64(a.Value) < b.(*Float).Value
+	case *Float:
+		if b, ok := b.(*Float); ok {
+			return a.Value < b.Value
+		}
+		return a.Value < float64(b.(*Integer).Value)
+	case *String:
+		return a.Value < b.(*String).Value
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	default:
+		return a.Inspect() < b.Inspect()
+	}
+}
+
+func keyRank(key Object) int {
+	switch key.Type() {
+	case INTEGER_OBJ, FLOAT_OBJ:
+		return 0
+	case STRING_OBJ:
+		return 1
+	case BOOLEAN_OBJ:
+		return 2
+	default:
+		return 3
+	}
+}
+
 type Integer struct {
 	Value int64
 }
@@ -87,6 +151,29 @@ func (f *Float) HashKey() HashKey {
 	return HashKey{Type: f.Type(), Value: h.Sum64()}
 }
 
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME_OBJ }
+func (t *Time) Inspect() string  { return t.Value.Format(time.RFC3339Nano) }
+
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() ObjectType { return DURATION_OBJ }
+func (d *Duration) Inspect() string  { return d.Value.String() }
+
+// Module is an imported file. Only its exported bindings are reachable.
+type Module struct {
+	Name    string
+	Exports map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return fmt.Sprintf("module %q", m.Name) }
+
 type String struct {
 	Value string
 }
@@ -117,8 +204,13 @@ func (b *Boolean) HashKey() HashKey {
 	return HashKey{Type: b.Type(), Value: value}
 }
 
+// Array and Hash values are Frozen by the freeze builtin. Scripts cannot
+// assign to elements and every builtin except delete returns a new value, so
+// delete refusing frozen hashes is all the evaluator enforces; Go code that
+// edits Elements or Pairs directly is not checked.
 type Array struct {
 	Elements []Object
+	Frozen   bool
 }
 
 func (a *Array) Type() ObjectType { return ARRAY_OBJ }
@@ -157,7 +249,7 @@ func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "Error: " + e.Message }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	Parameters []ast.Pattern
 	Body       *ast.BlockStatment
 	Env        *Environment
 }
@@ -182,18 +274,19 @@ func (f *Function) Inspect() string {
 }
 
 func NewEnvironment() *Environment {
-	return &Environment{store: make(map[string]Object), outer: nil}
+	return &Environment{store: make(map[string]Object), consts: make(map[string]bool), outer: nil}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := &Environment{store: make(map[string]Object)}
+	env := &Environment{store: make(map[string]Object), consts: make(map[string]bool)}
 	env.outer = outer
 	return env
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]Object
+	consts map[string]bool
+	outer  *Environment
 }
 
 func (ev *Environment) Get(name string) (Object, bool) {
@@ -203,11 +296,32 @@ func (ev *Environment) Get(name string) (Object, bool) {
 	}
 	return obj, ok
 }
+
+// Set binds name in this scope and returns val, or an *Error when name is
+// a constant of this scope. Constants of outer scopes may be shadowed.
 func (ev *Environment) Set(name string, val Object) Object {
+	if ev.consts[name] {
+		return redeclaredConstant(name)
+	}
+	ev.store[name] = val
+	return val
+}
+
+// SetConst binds name in this scope as a constant, failing like Set when
+// name already is one.
+func (ev *Environment) SetConst(name string, val Object) Object {
+	if ev.consts[name] {
+		return redeclaredConstant(name)
+	}
 	ev.store[name] = val
+	ev.consts[name] = true
 	return val
 }
 
+func redeclaredConstant(name string) *Error {
+	return &Error{Message: fmt.Sprintf("cannot redeclare constant %s", name)}
+}
+
 type Builtin struct {
 	Fn BuiltinFunction
 }
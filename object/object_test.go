@@ -19,3 +19,55 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("string with different content has same hash keys")
 	}
 }
+
+func TestHashSortedPairs(t *testing.T) {
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	for _, key := range []Object{
+		&String{Value: "b"},
+		&Boolean{Value: true},
+		&Integer{Value: 10},
+		&String{Value: "a"},
+		&Float{Value: 2.5},
+		&Boolean{Value: false},
+		&Integer{Value: -1},
+	} {
+		hash.Pairs[key.(Hashable).HashKey()] = HashPair{Key: key, Value: &Null{}}
+	}
+
+	expected := []string{"-1", "2.500000", "10", "a", "b", "false", "true"}
+	pairs := hash.SortedPairs()
+	if len(pairs) != len(expected) {
+		t.Fatalf("wrong num of pairs. got=%d", len(pairs))
+	}
+	for idx, pair := range pairs {
+		if pair.Key.Inspect() != expected[idx] {
+			t.Errorf("pairs[%d] has wrong key. expected=%q, got=%q", idx, expected[idx], pair.Key.Inspect())
+		}
+	}
+}
+
+func TestEnvironmentConstants(t *testing.T) {
+	env := NewEnvironment()
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+
+	if result := env.SetConst("x", one); result != one {
+		t.Fatalf("SetConst returned %v", result)
+	}
+	for _, result := range []Object{env.Set("x", two), env.SetConst("x", two)} {
+		errObj, ok := result.(*Error)
+		if !ok || errObj.Message != "cannot redeclare constant x" {
+			t.Errorf("expected redeclaration error. got=%v", result)
+		}
+	}
+	if value, _ := env.Get("x"); value != one {
+		t.Errorf("constant changed to %v", value)
+	}
+
+	inner := NewEnclosedEnvironment(env)
+	if result := inner.Set("x", two); result != two {
+		t.Errorf("shadowing an outer constant failed: %v", result)
+	}
+	if value, _ := env.Get("x"); value != one {
+		t.Errorf("shadowing changed the outer constant to %v", value)
+	}
+}
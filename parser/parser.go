@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/arthurlee945/monkey.on/ast"
 	"github.com/arthurlee945/monkey.on/lexer"
@@ -15,15 +16,18 @@ const (
 	LOWEST
 	EQUALS      // ==
 	LESSGREATER // > or <
+	PIPE        // x |> f
 	SUM         // +
 	PRODUCT     // *
 	MODULO      // %
 	PREFIX      // -X or !X
+	POWER       // **
 	CALL        // myFunc(x)
 	INDEX       // array[index]
 )
 
 var precedence = map[token.TokenType]int{
+	token.PIPE:     PIPE,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
@@ -33,8 +37,10 @@ var precedence = map[token.TokenType]int{
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.POWER:    POWER,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
 }
 
 type (
@@ -49,6 +55,7 @@ type Parser struct {
 	peekToken token.Token
 
 	errors         []string
+	blockDepth     int // how many { } blocks enclose curToken
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -68,6 +75,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE, p.parseTemplateLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
@@ -76,6 +84,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	//infix
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -84,12 +93,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.MODULO, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	return p
 }
 func (p *Parser) ParseProgram() *ast.Program {
@@ -123,10 +135,14 @@ func (p *Parser) nextToken() {
 
 func (p *Parser) parseStatment() ast.Statement {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -135,12 +151,19 @@ func (p *Parser) parseStatment() ast.Statement {
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Pattern = p.parsePattern(false)
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -156,6 +179,68 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+// parseImportStatement parses import "path" as name and
+// import { a, b } from "path". `as` and `from` are only keywords here.
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		for {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+			if !p.peekTokenIs(token.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.expectPeek(token.RBRACE) || !p.expectPeekWord("from") || !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	} else {
+		if !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeekWord("as") || !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	if p.blockDepth > 0 {
+		p.errors = append(p.errors, "export is only allowed at the top level")
+		return nil
+	}
+	p.nextToken()
+	next := p.curToken.Type
+	statement, ok := p.parseStatment().(*ast.LetStatement)
+	if !ok {
+		msg := fmt.Sprintf("expected next token to be LET or CONST, got %s instead", next)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	if statement == nil {
+		return nil
+	}
+	stmt.Statement = statement
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
@@ -264,6 +349,75 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	template := &ast.TemplateLiteral{Token: p.curToken}
+	raw := p.curToken.Literal
+
+	for {
+		start := strings.Index(raw, "${")
+		if start < 0 {
+			template.Strings = append(template.Strings, raw)
+			return template
+		}
+		template.Strings = append(template.Strings, raw[:start])
+
+		end := templateExpressionEnd(raw, start+2)
+		if end < 0 {
+			msg := fmt.Sprintf("unterminated ${ in template %q", p.curToken.Literal)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		exp := p.parseTemplateExpression(raw[start+2 : end])
+		if exp == nil {
+			return nil
+		}
+		template.Expressions = append(template.Expressions, exp)
+		raw = raw[end+1:]
+	}
+}
+
+// parseTemplateExpression parses the source between ${ and } with a parser
+// of its own, folding its errors into ours.
+func (p *Parser) parseTemplateExpression(src string) ast.Expression {
+	sub := New(lexer.New(src))
+	exp := sub.parseExpression(LOWEST)
+	if len(sub.errors) == 0 && !sub.peekTokenIs(token.EOF) {
+		sub.errors = append(sub.errors, fmt.Sprintf("unexpected %s", sub.peekToken.Type))
+	}
+
+	for _, msg := range sub.errors {
+		p.errors = append(p.errors, fmt.Sprintf("in template expression %q: %s", src, msg))
+	}
+	if len(sub.errors) != 0 {
+		return nil
+	}
+	return exp
+}
+
+// templateExpressionEnd finds the } closing an expression that starts at
+// from, skipping nested braces and string literals. It returns -1 if there is
+// none.
+func templateExpressionEnd(raw string, from int) int {
+	depth := 1
+	inString := false
+	for idx := from; idx < len(raw); idx++ {
+		switch ch := raw[idx]; {
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -316,11 +470,41 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IFExpression{Token: p.curToken}
 
+	for {
+		arm := p.parseIfArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.ELSE) {
+			return expression
+		}
+		p.nextToken()
+
+		if !p.peekTokenIs(token.IF) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Alternative = p.parseBlockStatment()
+
+	return expression
+}
+
+// parseIfArm parses the (condition) { consequence } following an if.
+func (p *Parser) parseIfArm() *ast.IfArm {
+	arm := &ast.IfArm{}
+
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
 	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
+	arm.Condition = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
@@ -328,16 +512,53 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	expression.Consequence = p.parseBlockStatment()
+	arm.Consequence = p.parseBlockStatment()
 
-	if p.peekTokenIs(token.ELSE) {
-		p.nextToken()
+	return arm
+}
+
+// parseMatchExpression parses match (subject) { pattern if guard => body, ... }.
+// A body starting with { is a block, so a hash literal body needs parentheses.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
 
-		if !p.expectPeek(token.LBRACE) {
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := &ast.MatchArm{Pattern: p.parsePattern(true)}
+		if arm.Pattern == nil {
+			return nil
+		}
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		if p.curTokenIs(token.LBRACE) {
+			arm.Body = p.parseBlockStatment()
+		} else if arm.Body = p.parseExpression(LOWEST); arm.Body == nil {
 			return nil
 		}
+		expression.Arms = append(expression.Arms, arm)
 
-		expression.Alternative = p.parseBlockStatment()
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
 	}
 
 	return expression
@@ -357,29 +578,170 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionParameters() []ast.Pattern {
+	params := []ast.Pattern{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return params
 	}
 	p.nextToken()
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	param := p.parsePattern(false)
+	if param == nil {
+		return nil
+	}
+	params = append(params, param)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		param := p.parsePattern(false)
+		if param == nil {
+			return nil
+		}
+		params = append(params, param)
 	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	return params
+}
+
+// parsePattern parses a binding target starting at the current token: a
+// name, an array pattern [a, [b, c], ...rest] or a hash pattern
+// {a, b: c, ...rest}. Refutable patterns, the ones in match arms, may also
+// contain literals that the value has to equal.
+func (p *Parser) parsePattern(refutable bool) ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET:
+		return p.parseArrayPattern(refutable)
+	case token.LBRACE:
+		return p.parseHashPattern(refutable)
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE, token.MINUS:
+		if refutable {
+			return p.parseLiteralPattern()
+		}
+		fallthrough
+	default:
+		msg := fmt.Sprintf("expected a name or pattern, got %s instead", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+func (p *Parser) parseLiteralPattern() ast.Pattern {
+	pattern := &ast.LiteralPattern{Token: p.curToken}
+
+	if p.curTokenIs(token.MINUS) {
+		if !p.peekTokenIs(token.INT) && !p.peekTokenIs(token.FLOAT) {
+			msg := fmt.Sprintf("expected a number after - in pattern, got %s instead", p.peekToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		negative := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
+		p.nextToken()
+		if negative.Right = p.parseLiteral(); negative.Right == nil {
+			return nil
+		}
+		pattern.Value = negative
+		return pattern
+	}
+	if pattern.Value = p.parseLiteral(); pattern.Value == nil {
+		return nil
+	}
+
+	return pattern
+}
+
+// parseLiteral parses the single literal token at the current position.
+// Unlike parseExpression it never goes on to an operator, index or member
+// access, so a pattern such as "a"[0] is rejected by the caller instead.
+func (p *Parser) parseLiteral() ast.Expression {
+	return p.prefixParseFns[p.curToken.Type]()
+}
+
+func (p *Parser) parseArrayPattern(refutable bool) ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+		p.nextToken()
+		element := p.parsePattern(refutable)
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
+func (p *Parser) parseHashPattern(refutable bool) ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+		p.nextToken()
+		pair := &ast.HashPatternPair{}
+		switch p.curToken.Type {
+		case token.IDENT:
+			pair.Key = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		case token.STRING, token.INT, token.TRUE, token.FALSE:
+			if pair.Key = p.parseLiteral(); pair.Key == nil {
+				return nil
+			}
+		default:
+			msg := fmt.Sprintf("expected a hash pattern key, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		if ident, ok := pair.Key.(*ast.Identifier); ok && !p.peekTokenIs(token.COLON) {
+			// {name} is short for {name: name}
+			pair.Value = ident
+		} else {
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+			p.nextToken()
+			if pair.Value = p.parsePattern(refutable); pair.Value == nil {
+				return nil
+			}
+		}
+		pattern.Pairs = append(pattern.Pairs, pair)
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return pattern
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
@@ -403,6 +765,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 	curPrecendence := p.curPrecendence()
+	if expression.Token.Type == token.POWER {
+		// right associative: 2 ** 3 ** 2 is 2 ** (3 ** 2)
+		curPrecendence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(curPrecendence)
 	return expression
@@ -421,6 +787,27 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: object}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	exp := &ast.PipeExpression{Token: p.curToken, Left: left}
+
+	precedence := p.curPrecendence()
+	p.nextToken()
+	exp.Right = p.parseExpression(precedence)
+
+	return exp
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
@@ -431,6 +818,9 @@ func (p *Parser) parseBlockStatment() *ast.BlockStatment {
 	block := &ast.BlockStatment{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.blockDepth++
+	defer func() { p.blockDepth-- }()
+
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
@@ -482,6 +872,18 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	}
 }
 
+// expectPeekWord is expectPeek for contextual keywords such as `as`, which
+// are ordinary identifiers everywhere else.
+func (p *Parser) expectPeekWord(word string) bool {
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == word {
+		p.nextToken()
+		return true
+	}
+	msg := fmt.Sprintf("expected next token to be %q, got %s instead", word, p.peekToken.Type)
+	p.errors = append(p.errors, msg)
+	return false
+}
+
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tokenType] = fn
 }
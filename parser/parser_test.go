@@ -43,6 +43,104 @@ func TestLetStatements(t *testing.T) {
 }
 
 // RETURN TEST
+func TestDestructuringPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		names    []string
+	}{
+		{"let [a, b] = pair;", "let [a, b] = pair;", []string{"a", "b"}},
+		{"let [first, ...rest] = xs", "let [first, ...rest] = xs;", []string{"first", "rest"}},
+		{"let [] = xs;", "let [] = xs;", []string{}},
+		{"let [[a, b], {c}] = xs;", "let [[a, b], {c}] = xs;", []string{"a", "b", "c"}},
+		{"let {name, age: years} = person;", "let {name, age: years} = person;", []string{"name", "years"}},
+		{"let {pos: [x, y], ...others} = p;", "let {pos: [x, y], ...others} = p;", []string{"x", "y", "others"}},
+		{"let f = fn([a, b], {c}) { a };", "let f = fn([a, b], {c}) a;", []string{"f"}},
+	}
+
+	for _, tt := range tests {
+		program := prepTest(t, tt.input)
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt := program.Statements[0].(*ast.LetStatement)
+		names := []string{}
+		for _, ident := range stmt.Names() {
+			names = append(names, ident.Value)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+			t.Errorf("stmt.Names() wrong. expected=%v, got=%v", tt.names, names)
+		}
+	}
+}
+
+func TestDestructuringPatternErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, 1] = xs;", "expected a name or pattern, got INT instead"},
+		{"let [a b] = xs;", "expected next token to be ,, got IDENT instead"},
+		{"let [...rest, a] = xs;", "expected next token to be ], got , instead"},
+		{"let [...] = xs;", "expected next token to be IDENT, got ] instead"},
+		{"let {[a]} = h;", "expected a hash pattern key, got [ instead"},
+		{`let {"a"} = h;`, "expected next token to be :, got } instead"},
+		{`let {"a"[0]: b} = h;`, "expected next token to be :, got [ instead"},
+		{"let {1.x: b} = h;", "expected next token to be :, got . instead"},
+		{`let {"a" ** 2: b} = h;`, "expected next token to be :, got ** instead"},
+		{`match (x) { "a"[0] => 1 }`, "expected next token to be =>, got [ instead"},
+		{"let {a: } = h;", "expected a name or pattern, got } instead"},
+		{"fn(a, 2) { a }", "expected a name or pattern, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const limit = 10;", "const limit = 10;"},
+		{"const [a, b] = pair", "const [a, b] = pair;"},
+		{"export const answer = 42;", "export const answer = 42;"},
+	}
+
+	for _, tt := range tests {
+		program := prepTest(t, tt.input)
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if export, isExport := program.Statements[0].(*ast.ExportStatement); isExport {
+			stmt, ok = export.Statement, true
+		}
+		if !ok || !stmt.IsConst() {
+			t.Errorf("statement is not a const statement. got=%T", program.Statements[0])
+		}
+	}
+	if prepTest(t, "let x = 1;").Statements[0].(*ast.LetStatement).IsConst() {
+		t.Errorf("let statement reported as const")
+	}
+
+	p := New(lexer.New("const = 1;"))
+	p.ParseProgram()
+	expected := "expected next token to be IDENT, got = instead"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -74,6 +172,89 @@ func TestReturnStatement(t *testing.T) {
 }
 
 // IDENTIFIER TEST
+func TestImportStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedPath  string
+		expectedAlias string
+		expectedNames []string
+		expected      string
+	}{
+		{`import "lib/math.mk" as math;`, "lib/math.mk", "math", nil, `import "lib/math.mk" as math;`},
+		{`import { add, sub } from "./ops"`, "./ops", "", []string{"add", "sub"}, `import { add, sub } from "./ops";`},
+	}
+
+	for _, tt := range tests {
+		program := prepTest(t, tt.input)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statments does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ImportStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Path.Value != tt.expectedPath {
+			t.Errorf("stmt.Path wrong. expected=%q, got=%q", tt.expectedPath, stmt.Path.Value)
+		}
+		if tt.expectedAlias != "" && (stmt.Alias == nil || stmt.Alias.Value != tt.expectedAlias) {
+			t.Errorf("stmt.Alias wrong. expected=%q, got=%v", tt.expectedAlias, stmt.Alias)
+		}
+		if len(stmt.Names) != len(tt.expectedNames) {
+			t.Fatalf("stmt.Names has wrong length. expected=%d, got=%d", len(tt.expectedNames), len(stmt.Names))
+		}
+		for idx, name := range stmt.Names {
+			testIdentifier(t, name, tt.expectedNames[idx])
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	program := prepTest(t, "export let double = fn(x) { x * 2 };")
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statments does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.ExportStatement. got=%T", program.Statements[0])
+	}
+	if !testLetStatements(t, stmt.Statement, "double") {
+		return
+	}
+	if stmt.String() != "export let double = fn(x) (x * 2);" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestModuleStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib"`, `expected next token to be "as", got EOF instead`},
+		{`import "lib" as`, "expected next token to be IDENT, got EOF instead"},
+		{`import lib`, "expected next token to be STRING, got IDENT instead"},
+		{`import { } from "lib"`, "expected next token to be IDENT, got } instead"},
+		{`import { a b } from "lib"`, "expected next token to be }, got IDENT instead"},
+		{`import { a } "lib"`, `expected next token to be "from", got STRING instead`},
+		{`export fn(x) { x }`, "expected next token to be LET or CONST, got FUNCTION instead"},
+		{`let f = fn() { export let a = 1; };`, "export is only allowed at the top level"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "momono;"
 
@@ -158,6 +339,66 @@ func TestStringLiteralExpression(t *testing.T) {
 }
 
 // ARRAY PARSE TEST
+func TestTemplateLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input               string
+		expectedStrings     []string
+		expectedExpressions []string
+		expected            string
+	}{
+		{"`plain`", []string{"plain"}, []string{}, "`plain`"},
+		{"``", []string{""}, []string{}, "``"},
+		{"`Hello ${name}!`", []string{"Hello ", "!"}, []string{"name"}, "`Hello ${name}!`"},
+		{"`${a + b * 2}`", []string{"", ""}, []string{"(a + (b * 2))"}, "`${(a + (b * 2))}`"},
+		{"`${x}${y}`", []string{"", "", ""}, []string{"x", "y"}, "`${x}${y}`"},
+		{"`${ {\"a\": 1}[\"a\"] } and ${\"}\"}`", []string{"", " and ", ""}, []string{"({a : 1}[a])", "}"}, "`${({a : 1}[a])} and ${}}`"},
+	}
+
+	for _, tt := range tests {
+		stmt := prepExpressionTest(t, tt.input)
+		template, ok := stmt.Expression.(*ast.TemplateLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.TemplateLiteral. got=%T", stmt.Expression)
+		}
+
+		if strings.Join(template.Strings, "|") != strings.Join(tt.expectedStrings, "|") {
+			t.Errorf("template.Strings wrong. expected=%q, got=%q", tt.expectedStrings, template.Strings)
+		}
+		if len(template.Expressions) != len(tt.expectedExpressions) {
+			t.Fatalf("template has wrong num of expressions. expected=%d, got=%d", len(tt.expectedExpressions), len(template.Expressions))
+		}
+		for i, exp := range template.Expressions {
+			if exp.String() != tt.expectedExpressions[i] {
+				t.Errorf("expressions[%d] wrong. expected=%q, got=%q", i, tt.expectedExpressions[i], exp.String())
+			}
+		}
+		if template.String() != tt.expected {
+			t.Errorf("template.String() wrong. expected=%q, got=%q", tt.expected, template.String())
+		}
+	}
+}
+
+func TestTemplateLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`${name`", "unterminated ${ in template \"${name\""},
+		{"`${}`", "in template expression \"\": no prefix parse function for EOF found"},
+		{"`${a b}`", "in template expression \"a b\": unexpected IDENT"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestParsingArrayLiteral(t *testing.T) {
 	input := `[1,  6 * 2,  6 - 2, "monkey"]`
 
@@ -350,6 +591,25 @@ func TestIndexExpression(t *testing.T) {
 }
 
 // PREFIX TEST
+func TestMemberExpression(t *testing.T) {
+	stmt := prepExpressionTest(t, "monkey.name")
+	member, ok := stmt.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MemberExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, member.Object, "monkey") {
+		return
+	}
+	testIdentifier(t, member.Property, "name")
+
+	p := New(lexer.New("monkey.;"))
+	p.ParseProgram()
+	expected := "expected next token to be IDENT, got ; instead"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTest := []struct {
 		input       string
@@ -434,6 +694,24 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"a * b * c", "((a * b) * c)"},
 		{"a * b / c", "((a * b) / c)"},
 		{"a * b % c", "(a * (b % c))"},
+		{"a * b ** c", "(a * (b ** c))"},
+		{"a ** b ** c", "(a ** (b ** c))"},
+		{"-a ** b", "(-(a ** b))"},
+		{"a ** -b", "(a ** (-b))"},
+		{"f(a) ** b[0]", "(f(a) ** (b[0]))"},
+		{"a.b.c", "((a.b).c)"},
+		{"a.b(c)", "(a.b)(c)"},
+		{"-a.b", "(-(a.b))"},
+		{"a.b[0].c", "(((a.b)[0]).c)"},
+		{"f(x).y * 2", "((f(x).y) * 2)"},
+		{"a * b.c ** d", "(a * ((b.c) ** d))"},
+		{"a |> f", "(a |> f)"},
+		{"a + 1 |> f(b * 2)", "((a + 1) |> f((b * 2)))"},
+		{"a |> f |> g(b)", "((a |> f) |> g(b))"},
+		{"a |> f == b |> g", "((a |> f) == (b |> g))"},
+		{"a < b |> f", "(a < (b |> f))"},
+		{"a |> f != b", "((a |> f) != b)"},
+		{"[1, 2] |> m.map(fn(x) { x })", "([1, 2] |> (m.map)(fn(x) x))"},
 		{"a + b / c", "(a + (b / c))"},
 		{"a + b * c % 5 / d + e - c", "(((a + ((b * (c % 5)) / d)) + e) - c)"},
 		{"3 + 6; -25 * 6 % 3", "(3 + 6)((-25) * (6 % 3))"},
@@ -484,17 +762,21 @@ func TestIfExpression(t *testing.T) {
 		t.Fatalf("stmt.Expresion is not ast.ConditionalExpression, got=%T", stmt.Expression)
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+	if len(exp.Arms) != 1 {
+		t.Fatalf("exp.Arms is not 1 arm. got=%d", len(exp.Arms))
+	}
+
+	if !testInfixExpression(t, exp.Arms[0].Condition, "x", "<", "y") {
 		return
 	}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("consequence is not 1 statements. got=%d\n", len(exp.Consequence.Statements))
+	if len(exp.Arms[0].Consequence.Statements) != 1 {
+		t.Errorf("consequence is not 1 statements. got=%d\n", len(exp.Arms[0].Consequence.Statements))
 	}
 
-	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	consequence, ok := exp.Arms[0].Consequence.Statements[0].(*ast.ExpressionStatement)
 	if !ok {
-		t.Fatalf("consequence.Statements[0] is not ast.ExpressionStatement, got=%T", exp.Consequence.Statements[0])
+		t.Fatalf("consequence.Statements[0] is not ast.ExpressionStatement, got=%T", exp.Arms[0].Consequence.Statements[0])
 	}
 
 	if !testIdentifier(t, consequence.Expression, "x") {
@@ -514,17 +796,21 @@ func TestIfElseExpression(t *testing.T) {
 		t.Fatalf("stmt.Expresion is not ast.ConditionalExpression, got=%T", stmt.Expression)
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+	if len(exp.Arms) != 1 {
+		t.Fatalf("exp.Arms is not 1 arm. got=%d", len(exp.Arms))
+	}
+
+	if !testInfixExpression(t, exp.Arms[0].Condition, "x", "<", "y") {
 		return
 	}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("consequence is not 1 statements. got=%d\n", len(exp.Consequence.Statements))
+	if len(exp.Arms[0].Consequence.Statements) != 1 {
+		t.Errorf("consequence is not 1 statements. got=%d\n", len(exp.Arms[0].Consequence.Statements))
 	}
 
-	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	consequence, ok := exp.Arms[0].Consequence.Statements[0].(*ast.ExpressionStatement)
 	if !ok {
-		t.Fatalf("consequence.Statements[0] is not ast.ExpressionStatement, got=%T", exp.Consequence.Statements[0])
+		t.Fatalf("consequence.Statements[0] is not ast.ExpressionStatement, got=%T", exp.Arms[0].Consequence.Statements[0])
 	}
 
 	if !testIdentifier(t, consequence.Expression, "x") {
@@ -541,7 +827,98 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else if (x == 0) { 0 } else { z }`
+	stmt := prepExpressionTest(t, input)
+	exp, ok := stmt.Expression.(*ast.IFExpression)
+
+	if !ok {
+		t.Fatalf("stmt.Expresion is not ast.IFExpression, got=%T", stmt.Expression)
+	}
+	if len(exp.Arms) != 3 {
+		t.Fatalf("exp.Arms is not 3 arms. got=%d", len(exp.Arms))
+	}
+	testInfixExpression(t, exp.Arms[0].Condition, "x", "<", "y")
+	testInfixExpression(t, exp.Arms[1].Condition, "x", ">", "y")
+	testInfixExpression(t, exp.Arms[2].Condition, "x", "==", 0)
+
+	if exp.Alternative == nil {
+		t.Fatalf("exp.Alternative is nil")
+	}
+	expected := "if (x < y) x else if (x > y) y else if (x == 0) 0 else z"
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. expected=%q, got=%q", expected, exp.String())
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`if (a) { 1 } else if (b) { 2 }`, "if a 1 else if b 2"},
+		{`if (a) { 1 }`, "if a 1"},
+		{`if (a) { 1 } else { if (b) { 2 } }`, "if a 1 else if b 2"},
+	}
+	for _, tt := range tests {
+		if actual := prepTest(t, tt.input).String(); actual != tt.expected {
+			t.Errorf("program.String() wrong. expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.New(`if (a) { 1 } else if b { 2 }`))
+	p.ParseProgram()
+	expectedErr := "expected next token to be (, got IDENT instead"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expectedErr {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expectedErr, p.Errors())
+	}
+}
+
 // FUNCTION TEST
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (x) { 1 => "one", _ => "many" }`, `match (x) { 1 => one, _ => many }`},
+		{`match (p) { [a, b] if a > b => a, [a, ...rest] => rest }`, `match (p) { [a, b] if (a > b) => a, [a, ...rest] => rest }`},
+		{`match (e) { {"type": "add", args: [l, r]} => l + r, }`, `match (e) { {type: add, args: [l, r]} => (l + r) }`},
+		{`match (n) { -1 => true, 2.5 => false, x => { let y = x; y } }`, `match (n) { (-1) => true, 2.5 => false, x => let y = x;y }`},
+		{`match (x) { }`, `match (x) {  }`},
+	}
+
+	for _, tt := range tests {
+		stmt := prepExpressionTest(t, tt.input)
+		if _, ok := stmt.Expression.(*ast.MatchExpression); !ok {
+			t.Fatalf("exp not *ast.MatchExpression. got=%T", stmt.Expression)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match x { _ => 1 }`, "expected next token to be (, got IDENT instead"},
+		{`match (x) { 1 2 }`, "expected next token to be =>, got INT instead"},
+		{`match (x) { 1 => 1 2 => 2 }`, "expected next token to be ,, got INT instead"},
+		{`match (x) { - a => 1 }`, "expected a number after - in pattern, got IDENT instead"},
+		{`match (x) { fn => 1 }`, "expected a name or pattern, got FUNCTION instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong parser errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(a, b){ a + b; }`
 	stmt := prepExpressionTest(t, input)
@@ -29,7 +29,7 @@ func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 
 	for {
-		fmt.Print(PROMPT)
+		fmt.Fprint(out, PROMPT)
 		scanned := scanner.Scan()
 
 		if !scanned {
@@ -41,7 +41,7 @@ func Start(in io.Reader, out io.Writer) {
 		l := lexer.New(line)
 
 		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Printf("%+v\n", tok)
+			fmt.Fprintf(out, "%+v\n", tok)
 		}
 	}
 }
@@ -50,7 +50,7 @@ func StartParser(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 
 	for {
-		fmt.Print(PROMPT)
+		fmt.Fprint(out, PROMPT)
 		scanned := scanner.Scan()
 
 		if !scanned {
@@ -74,18 +74,24 @@ func StartParser(in io.Reader, out io.Writer) {
 }
 
 func StartEvaluator(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	// scripts calling `input` read from the same buffer as the prompt, so
+	// neither swallows lines meant for the other
+	reader := bufio.NewReader(in)
 	env := object.NewEnvironment()
+	// the REPL works on files and imports relative to where it was started
+	cwd := evaluator.DirFS(".")
+	e := evaluator.New(evaluator.FULL_PROFILE)
+	e.SetFileSystem(cwd)
+	e.SetModuleLoader(evaluator.NewModuleLoader(cwd))
+	e.SetInput(reader)
+	e.SetOutput(out)
 	for {
-		fmt.Print(PROMPT)
-		scanned := scanner.Scan()
-
-		if !scanned {
+		fmt.Fprint(out, PROMPT)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
 			return
 		}
 
-		line := scanner.Text()
-
 		l := lexer.New(line)
 		p := parser.New(l)
 		program := p.ParseProgram()
@@ -95,7 +101,7 @@ func StartEvaluator(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		evaluated := e.Eval(program, env)
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
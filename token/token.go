@@ -12,19 +12,23 @@ const (
 	EOF     = "EOF"
 
 	// Identifier + literals(Func, Var, etc...)
-	IDENT  = "IDENT"  //add, foo, bar...
-	INT    = "INT"    //1,2,3 ...
-	FLOAT  = "FLOAT"  //8.27, 2.32 ...
-	STRING = "STRING" // "monkey"
+	IDENT    = "IDENT"    //add, foo, bar...
+	INT      = "INT"      //1,2,3 ...
+	FLOAT    = "FLOAT"    //8.27, 2.32 ...
+	STRING   = "STRING"   // "monkey"
+	TEMPLATE = "TEMPLATE" // `monkey ${name}`
 
 	//OPERATOR
 	ASSIGN   = "="
+	ARROW    = "=>"
 	PLUS     = "+"
 	MINUS    = "-"
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
 	MODULO   = "%"
+	POWER    = "**"
+	PIPE     = "|>"
 
 	LT     = "<"
 	GT     = ">"
@@ -35,6 +39,8 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -46,21 +52,29 @@ const (
 	//Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
 	"fn":     FUNCTION,
 	"let":    LET,
+	"const":  CONST,
 	"true":   TRUE,
 	"false":  FALSE,
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"import": IMPORT,
+	"export": EXPORT,
+	"match":  MATCH,
 }
 
 func LookupIndentifier(ident string) TokenType {